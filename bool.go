@@ -0,0 +1,45 @@
+package ski
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cast"
+)
+
+// boolTokens the case-insensitive tokens of the bool kind, the defaults
+// extend the strconv.ParseBool set with the common scraped values.
+var boolTokens = struct {
+	sync.RWMutex
+	m map[string]bool
+}{m: map[string]bool{
+	"1": true, "t": true, "true": true, "y": true, "yes": true, "on": true,
+	"✓": true, "✔": true, "√": true, "是": true, "对": true, "有": true,
+	"0": false, "f": false, "false": false, "n": false, "no": false, "off": false,
+	"✗": false, "✘": false, "×": false, "否": false, "错": false, "无": false,
+}}
+
+// RegisterBoolToken registers the case-insensitive token of the bool kind, eg: "enabled", "はい".
+// The registered token overrides the default value of the same token.
+func RegisterBoolToken(token string, value bool) {
+	boolTokens.Lock()
+	defer boolTokens.Unlock()
+	boolTokens.m[strings.ToLower(strings.TrimSpace(token))] = value
+}
+
+// toBool converts the value to bool, the string is matched against the bool tokens,
+// see RegisterBoolToken. The unknown token returns an error.
+func toBool(v any) (bool, error) {
+	s, ok := v.(string)
+	if !ok {
+		return cast.ToBoolE(v)
+	}
+	boolTokens.RLock()
+	defer boolTokens.RUnlock()
+	b, ok := boolTokens.m[strings.ToLower(strings.TrimSpace(s))]
+	if !ok {
+		return false, fmt.Errorf("unknown bool token %q", s)
+	}
+	return b, nil
+}
@@ -0,0 +1,68 @@
+package ski
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoolKind(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		arg  any
+		want bool
+		err  string
+	}{
+		{"true", true, ""},
+		{"True", true, ""},
+		{" YES ", true, ""},
+		{"on", true, ""},
+		{"✓", true, ""},
+		{"是", true, ""},
+		{"1", true, ""},
+		{"false", false, ""},
+		{"No", false, ""},
+		{"OFF", false, ""},
+		{"✗", false, ""},
+		{"否", false, ""},
+		{"0", false, ""},
+		{1, true, ""},
+		{0, false, ""},
+		{true, true, ""},
+		{nil, false, ""},
+		{"maybe", false, `unknown bool token "maybe"`},
+		{"", false, `unknown bool token ""`},
+	}
+	for _, c := range testCases {
+		v, err := KindBool.Exec(context.Background(), c.arg)
+		if c.err != "" {
+			assert.EqualError(t, err, c.err)
+			continue
+		}
+		if assert.NoError(t, err, c.arg) {
+			assert.Equal(t, c.want, v, c.arg)
+		}
+	}
+}
+
+func TestRegisterBoolToken(t *testing.T) {
+	RegisterBoolToken("Enabled", true)
+	RegisterBoolToken("はい", true)
+	t.Cleanup(func() {
+		boolTokens.Lock()
+		delete(boolTokens.m, "enabled")
+		delete(boolTokens.m, "はい")
+		boolTokens.Unlock()
+	})
+
+	exec, err := Compile(`$kind: bool`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, s := range []string{"enabled", "ENABLED", "はい"} {
+		v, err := exec.Exec(context.Background(), s)
+		assert.NoError(t, err)
+		assert.Equal(t, true, v)
+	}
+}
@@ -0,0 +1,90 @@
+package ski
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen the circuit breaker of the host is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// defaultCircuitCooldown the default duration the circuit stays open
+const defaultCircuitCooldown = 30 * time.Second
+
+// breaker the per-host circuit breaker
+type breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	circuits  map[string]*circuit
+}
+
+type circuit struct {
+	failures  int
+	openUntil time.Time
+	probing   bool // half-open, the probe request is in flight
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	if cooldown <= 0 {
+		cooldown = defaultCircuitCooldown
+	}
+	return &breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		circuits:  make(map[string]*circuit),
+	}
+}
+
+// allow reports ErrCircuitOpen if the circuit of the host is open,
+// after the cooldown only one probe request is allowed.
+func (b *breaker) allow(host string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[host]
+	if !ok || c.failures < b.threshold {
+		return nil
+	}
+	if c.probing || time.Now().Before(c.openUntil) {
+		return fmt.Errorf("%w: %s", ErrCircuitOpen, host)
+	}
+	c.probing = true
+	return nil
+}
+
+// release releases the probe without recording the result
+func (b *breaker) release(host string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c, ok := b.circuits[host]; ok {
+		c.probing = false
+	}
+}
+
+// done records the result of the request, the canceled requests are ignored.
+func (b *breaker) done(host string, err error, res *http.Response) {
+	if errors.Is(err, context.Canceled) {
+		b.release(host)
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[host]
+	if !ok {
+		c = new(circuit)
+		b.circuits[host] = c
+	}
+	c.probing = false
+	if err == nil && res.StatusCode < http.StatusInternalServerError {
+		delete(b.circuits, host)
+		return
+	}
+	c.failures++
+	if c.failures >= b.threshold {
+		c.openUntil = time.Now().Add(b.cooldown)
+	}
+}
@@ -2,8 +2,11 @@ package ski
 
 import (
 	"context"
+	"errors"
 	"maps"
+	"slices"
 	"sync"
+	"time"
 )
 
 // Context multiple values context
@@ -62,3 +65,138 @@ func WithValue(ctx context.Context, key, value any) context.Context {
 	}
 	return context.WithValue(ctx, key, value)
 }
+
+// ErrDeadlineExceeded the job deadline budget has elapsed
+var ErrDeadlineExceeded = errors.New("job deadline budget exceeded")
+
+var deadlineBudgetKey byte
+
+// WithDeadlineBudget set the total wall-clock budget from now for the whole job,
+// once the budget elapses the remaining executions and requests fail fast with ErrDeadlineExceeded.
+// Unlike context.WithTimeout, the in-flight requests are not canceled.
+func WithDeadlineBudget(ctx context.Context, budget time.Duration) context.Context {
+	return WithValue(ctx, &deadlineBudgetKey, time.Now().Add(budget))
+}
+
+// DeadlineBudget returns the deadline of the job budget, ok is false if not set.
+func DeadlineBudget(ctx context.Context) (deadline time.Time, ok bool) {
+	deadline, ok = ctx.Value(&deadlineBudgetKey).(time.Time)
+	return
+}
+
+// CheckDeadlineBudget returns ErrDeadlineExceeded if the job budget has elapsed.
+func CheckDeadlineBudget(ctx context.Context) error {
+	if deadline, ok := DeadlineBudget(ctx); ok && !time.Now().Before(deadline) {
+		return ErrDeadlineExceeded
+	}
+	return nil
+}
+
+var concurrencyKey byte
+
+// WithConcurrency set the maximum number of the map fields executed concurrently, default is 1.
+// The limit is shared by the nested maps, the field is executed in the current goroutine
+// if no worker is available. The result and the returned errors are in the declared order.
+func WithConcurrency(ctx context.Context, n int) context.Context {
+	if n < 2 {
+		return WithValue(ctx, &concurrencyKey, nil)
+	}
+	return WithValue(ctx, &concurrencyKey, make(chan struct{}, n-1))
+}
+
+// parallel calls fn with the index from 0 to n-1 concurrently up to the WithConcurrency limit,
+// returns after all calls are done.
+func parallel(ctx context.Context, n int, fn func(int)) {
+	sem, _ := ctx.Value(&concurrencyKey).(chan struct{})
+	if sem == nil || n < 2 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+			wg.Add(1)
+			go func(i int) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				fn(i)
+			}(i)
+		default:
+			fn(i)
+		}
+	}
+	wg.Wait()
+}
+
+// FieldError the error of the map field or the each element,
+// the Path is the dot separated field names and element indexes. eg: "items.0.name"
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string { return e.Path + ": " + e.Err.Error() }
+
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// ErrorCollector collects the errors of the map fields and each elements,
+// which are ignored to keep the partial result.
+type ErrorCollector struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (c *ErrorCollector) add(err error) {
+	c.mu.Lock()
+	c.errs = append(c.errs, err)
+	c.mu.Unlock()
+}
+
+// Errors returns the collected *FieldError in the execution order,
+// the order of the concurrent map fields is not deterministic, see WithConcurrency.
+func (c *ErrorCollector) Errors() []error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.errs)
+}
+
+// Err returns the joined collected errors, nil if no error.
+func (c *ErrorCollector) Err() error { return errors.Join(c.Errors()...) }
+
+var errorCollectorKey, fieldPathKey byte
+
+// WithErrorCollector returns a copy of parent context which collects the errors
+// of the executions, the caller decides whether the partial result is acceptable.
+func WithErrorCollector(ctx context.Context) (context.Context, *ErrorCollector) {
+	c := new(ErrorCollector)
+	return WithValue(ctx, &errorCollectorKey, c), c
+}
+
+// ErrorCollectorFromContext returns the ErrorCollector on context, nil if not set.
+func ErrorCollectorFromContext(ctx context.Context) *ErrorCollector {
+	c, _ := ctx.Value(&errorCollectorKey).(*ErrorCollector)
+	return c
+}
+
+// execField executes the field with the path, the error is recorded to the ErrorCollector if present.
+// The errors of the nested fields have been recorded with the full path, so they are skipped.
+// The path is also used by the Explainer.
+func execField(ctx context.Context, exec Executor, arg any, field string) (any, error) {
+	c := ErrorCollectorFromContext(ctx)
+	if c == nil && ExplainerFromContext(ctx) == nil {
+		return exec.Exec(ctx, arg)
+	}
+	if path, ok := ctx.Value(&fieldPathKey).(string); ok {
+		field = path + "." + field
+	}
+	v, err := exec.Exec(context.WithValue(ctx, &fieldPathKey, field), arg)
+	if fe := new(FieldError); c != nil && err != nil && !errors.As(err, &fe) {
+		c.add(&FieldError{Path: field, Err: err})
+	}
+	return v, err
+}
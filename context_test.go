@@ -26,3 +26,114 @@ func TestNewContext(t *testing.T) {
 
 	assert.Equal(t, "value4", ctx.Value("key4"))
 }
+
+func TestDeadlineBudget(t *testing.T) {
+	t.Parallel()
+	assert.NoError(t, CheckDeadlineBudget(context.Background()))
+
+	ctx := WithDeadlineBudget(context.Background(), 50*time.Millisecond)
+	assert.NoError(t, CheckDeadlineBudget(ctx))
+
+	exec := _pipe{_inc{}, _inc{}}
+	v, err := exec.Exec(ctx, 0)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 2, v)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	assert.ErrorIs(t, CheckDeadlineBudget(ctx), ErrDeadlineExceeded)
+	_, err = exec.Exec(ctx, 0)
+	assert.ErrorIs(t, err, ErrDeadlineExceeded)
+}
+
+func TestErrorCollector(t *testing.T) {
+	t.Parallel()
+	exec, err := Compile(`
+$map:
+  title:
+    $debug:
+  data:
+    $json.parse:
+  items:
+    $list:
+    $each:
+      $map:
+        id:
+          $json.parse:
+        name:
+          $required:
+            $string.strip_prefix: "{"`)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	v, err := exec.Exec(context.Background(), "{bad")
+	assert.NoError(t, err)
+	assert.Equal(t, "{bad", v.(map[string]any)["title"])
+
+	ctx, collector := WithErrorCollector(context.Background())
+	assert.Same(t, collector, ErrorCollectorFromContext(ctx))
+	v, err = exec.Exec(ctx, "{")
+	assert.ErrorIs(t, err, ErrRequired)
+	assert.Equal(t, "{", v.(map[string]any)["title"])
+
+	errs := collector.Errors()
+	if assert.Len(t, errs, 3) {
+		paths := make([]string, 0, len(errs))
+		for _, e := range errs {
+			var fe *FieldError
+			if assert.ErrorAs(t, e, &fe) {
+				paths = append(paths, fe.Path)
+			}
+		}
+		assert.ElementsMatch(t, []string{"data", "items.0.id", "items.0.name"}, paths)
+	}
+	assert.ErrorContains(t, collector.Err(), "items.0.name: required value is empty")
+	assert.ErrorContains(t, collector.Err(), "data: unexpected end of JSON input")
+
+	assert.Nil(t, ErrorCollectorFromContext(context.Background()))
+}
+
+type _sleep time.Duration
+
+func (s _sleep) Exec(_ context.Context, v any) (any, error) {
+	time.Sleep(time.Duration(s))
+	return v, nil
+}
+
+func TestConcurrency(t *testing.T) {
+	t.Parallel()
+	const delay = 50 * time.Millisecond
+	exec := _map{
+		String("a"), _pipe{_sleep(delay), _inc{}},
+		String("b"), _pipe{_sleep(delay), _dec{}},
+		String("c"), _map{
+			String("d"), _sleep(delay),
+			String("e"), _required{_pipe{_sleep(delay), _raw{nil}}},
+		},
+		String("f"), _required{_pipe{_sleep(delay), _raw{""}}},
+	}
+
+	run := func(ctx context.Context) (any, error, time.Duration) {
+		start := time.Now()
+		v, err := exec.Exec(ctx, 1)
+		return v, err, time.Since(start)
+	}
+
+	seq, seqErr, seqElapsed := run(context.Background())
+	assert.Equal(t, map[string]any{"a": 2, "b": 0, "c": map[string]any{"d": 1, "e": nil}, "f": ""}, seq)
+	assert.EqualError(t, seqErr, "c: e: required value is empty\nf: required value is empty")
+	assert.GreaterOrEqual(t, seqElapsed, 5*delay)
+
+	v, err, elapsed := run(WithConcurrency(context.Background(), 1))
+	assert.Equal(t, seq, v)
+	assert.Equal(t, seqErr, err)
+	assert.GreaterOrEqual(t, elapsed, 5*delay)
+
+	for i := 0; i < 5; i++ {
+		v, err, elapsed = run(WithConcurrency(context.Background(), 8))
+		assert.Equal(t, seq, v)
+		assert.Equal(t, seqErr, err)
+		assert.Less(t, elapsed, 3*delay)
+	}
+}
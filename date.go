@@ -0,0 +1,135 @@
+package ski
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// _date parse the date string and format it to the RFC3339 string,
+// the Iterator is parsed element-wise. If the layout is not specified,
+// the common layouts are tried in order, see defaultDateLayouts.
+//
+//	$date: 2006-01-02 # the layout only
+//	$date:
+//	  layout: 2006-01-02 15:04:05 # optional, the Go time layout
+//	  location: Asia/Shanghai     # the location of the date without time zone, default is UTC
+//	  timezone: America/New_York  # the output time zone, default is the parsed time zone
+type _date struct {
+	layouts  []string // nil means defaultDateLayouts
+	location *time.Location
+	timezone *time.Location
+}
+
+// defaultDateLayouts the layouts to try when the layout is not specified
+var defaultDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+	"2006/01/02",
+	"2006.01.02",
+	"2006年1月2日 15:04",
+	"2006年1月2日",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.RFC822Z,
+	time.RFC822,
+	time.ANSIC,
+	"Jan 2, 2006 15:04",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"2 Jan 2006 15:04",
+	"2 Jan 2006",
+	"2 January 2006",
+}
+
+func new_date(args ...Executor) (Executor, error) {
+	d := _date{location: time.UTC}
+	if len(args) == 1 {
+		if layout := ExecToString(args[0]); layout != "" {
+			d.layouts = []string{layout}
+		}
+		return d, nil
+	}
+	if len(args)%2 != 0 {
+		return nil, fmt.Errorf("unexpected arguments, expected layout, location or timezone mapping")
+	}
+	for i := 0; i < len(args); i += 2 {
+		value := ExecToString(args[i+1])
+		switch key := ExecToString(args[i]); key {
+		case "layout":
+			if value != "" {
+				d.layouts = []string{value}
+			}
+		case "location", "timezone":
+			loc, err := time.LoadLocation(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %s", key, err)
+			}
+			if key == "location" {
+				d.location = loc
+			} else {
+				d.timezone = loc
+			}
+		default:
+			return nil, fmt.Errorf("unknown date option %s", key)
+		}
+	}
+	return d, nil
+}
+
+func (d _date) Exec(ctx context.Context, arg any) (any, error) {
+	var t time.Time
+	switch v := arg.(type) {
+	case nil:
+		return nil, nil
+	case time.Time:
+		t = v
+	case Iterator:
+		ret := make([]any, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			date, err := d.Exec(ctx, v.At(i))
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, date)
+		}
+		return NewIterator(ret), nil
+	default:
+		str, err := cast.ToStringE(arg)
+		if err != nil {
+			return nil, err
+		}
+		if t, err = d.parse(strings.TrimSpace(str)); err != nil {
+			return nil, err
+		}
+	}
+	if d.timezone != nil {
+		t = t.In(d.timezone)
+	}
+	return t.Format(time.RFC3339), nil
+}
+
+func (d _date) parse(str string) (time.Time, error) {
+	layouts := d.layouts
+	if layouts == nil {
+		layouts = defaultDateLayouts
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, str, d.location); err == nil {
+			return t, nil
+		}
+	}
+	if d.layouts == nil {
+		return time.Time{}, fmt.Errorf("cannot parse date %q with the default layouts, specify the layout", str)
+	}
+	return time.Time{}, fmt.Errorf("cannot parse date %q with layouts %s", str, strings.Join(d.layouts, ", "))
+}
@@ -0,0 +1,57 @@
+package ski
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDate(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testCases := []struct {
+		schema string
+		arg    any
+		want   any
+		err    string
+	}{
+		{`$date:`, "2024-07-01 12:00:00", "2024-07-01T12:00:00Z", ""},
+		{`$date: { timezone: America/New_York }`, "2024-07-01 12:00:00", "2024-07-01T08:00:00-04:00", ""},
+		{`$date: { timezone: America/New_York }`, "2024-01-15 12:00:00", "2024-01-15T07:00:00-05:00", ""},
+		// the DST transition in New York happens at 2024-03-10 07:00 UTC
+		{`$date: { timezone: America/New_York }`, "2024-03-10T06:59:59Z", "2024-03-10T01:59:59-05:00", ""},
+		{`$date: { timezone: America/New_York }`, "2024-03-10T07:00:00Z", "2024-03-10T03:00:00-04:00", ""},
+		{`$date: { location: Asia/Shanghai, timezone: America/New_York }`, "2024-07-01 20:00:00", "2024-07-01T08:00:00-04:00", ""},
+		{`$date: { layout: "02/01/2006 15:04", location: Europe/Paris }`, "25/12/2024 18:30", "2024-12-25T18:30:00+01:00", ""},
+		{`$date:`, nil, nil, ""},
+		{`$date: 2006/01/02`, "2024/07/01", "2024-07-01T00:00:00Z", ""},
+		{`$date:`, "2024/07/01 08:30", "2024-07-01T08:30:00Z", ""},
+		{`$date:`, "2024年7月1日", "2024-07-01T00:00:00Z", ""},
+		{`$date:`, "Mon, 01 Jul 2024 12:00:00 +0800", "2024-07-01T12:00:00+08:00", ""},
+		{`$date:`, "July 1, 2024", "2024-07-01T00:00:00Z", ""},
+		{`$date:`, " 1 Jul 2024 ", "2024-07-01T00:00:00Z", ""},
+		{`$date: { location: Asia/Tokyo }`, "Jul 1, 2024 09:00", "2024-07-01T09:00:00+09:00", ""},
+		{`$date:`, NewIterator([]string{"2024-07-01", "2024.07.02"}), NewIterator([]any{"2024-07-01T00:00:00Z", "2024-07-02T00:00:00Z"}), ""},
+		{`$date:`, "yesterday", nil, `cannot parse date "yesterday" with the default layouts`},
+		{`$date: 2006-01-02`, "2024/07/01", nil, `cannot parse date "2024/07/01" with layouts 2006-01-02`},
+	}
+	for _, c := range testCases {
+		t.Run(c.schema, func(t *testing.T) {
+			exec, err := Compile(c.schema)
+			if !assert.NoError(t, err) {
+				return
+			}
+			v, err := exec.Exec(ctx, c.arg)
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.want, v)
+			}
+		})
+	}
+	_, err := Compile(`$date: { timezone: Mars/Olympus }`)
+	assert.ErrorContains(t, err, "invalid timezone")
+}
@@ -0,0 +1,200 @@
+package ski
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Decompressor returns the reader of the decoded content
+type Decompressor func(r io.Reader) (io.ReadCloser, error)
+
+var decompressors = struct {
+	sync.RWMutex
+	m map[string]Decompressor
+}{m: map[string]Decompressor{
+	"gzip":    func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) },
+	"deflate": inflate,
+	"zstd":    unzstd,
+}}
+
+// RegisterDecompressor registers the Decompressor of the Content-Encoding, eg: "br", "zstd".
+// The registered encodings are advertised by the Fetcher in the Accept-Encoding header,
+// the response of the unregistered encoding is passed through untouched.
+func RegisterDecompressor(encoding string, d Decompressor) {
+	decompressors.Lock()
+	defer decompressors.Unlock()
+	decompressors.m[strings.ToLower(encoding)] = d
+}
+
+// GetDecompressor returns the Decompressor of the Content-Encoding,
+// the "x-gzip" is equivalent to the "gzip".
+func GetDecompressor(encoding string) (Decompressor, bool) {
+	encoding = normalizeEncoding(encoding)
+	decompressors.RLock()
+	defer decompressors.RUnlock()
+	d, ok := decompressors.m[encoding]
+	return d, ok
+}
+
+// acceptEncoding returns the Accept-Encoding header value of the registered encodings
+func acceptEncoding() string {
+	decompressors.RLock()
+	defer decompressors.RUnlock()
+	encodings := make([]string, 0, len(decompressors.m))
+	for encoding := range decompressors.m {
+		encodings = append(encodings, encoding)
+	}
+	sort.Strings(encodings)
+	return strings.Join(encodings, ", ")
+}
+
+// inflate decodes the deflate content, the zlib format is specified by the
+// RFC 9110, but some servers send the raw deflate stream.
+func inflate(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+// unzstd decodes the zstd content, the window size is limited to 8MB as the RFC 9659
+func unzstd(r io.Reader) (io.ReadCloser, error) {
+	d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxWindow(8<<20))
+	if err != nil {
+		return nil, err
+	}
+	return d.IOReadCloser(), nil
+}
+
+// normalizeEncoding returns the lower case encoding, the "x-gzip" is equivalent to the "gzip".
+func normalizeEncoding(encoding string) string {
+	encoding = strings.ToLower(strings.TrimSpace(encoding))
+	if encoding == "x-gzip" {
+		return "gzip"
+	}
+	return encoding
+}
+
+// acceptedEncodings returns the encodings of the Accept-Encoding header value
+// except the "q=0", nil means any encoding is accepted.
+func acceptedEncodings(header string) map[string]bool {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		encoding, params, _ := strings.Cut(part, ";")
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				continue
+			}
+		}
+		switch encoding = normalizeEncoding(encoding); encoding {
+		case "":
+		case "*":
+			return nil
+		default:
+			accepted[encoding] = true
+		}
+	}
+	return accepted
+}
+
+// decompress decodes the response body by the Content-Encoding, the encodings are
+// applied in reverse order. If any encoding is not registered or not accepted by
+// the request Accept-Encoding, the body is untouched. If the limit is positive,
+// reading the decoded body returns ErrBodyTooLarge once the decoded size exceeds the limit.
+func decompress(res *http.Response, limit int64) {
+	if res.Body == nil || res.Body == http.NoBody || res.StatusCode == http.StatusNoContent ||
+		res.StatusCode == http.StatusNotModified || (res.Request != nil && res.Request.Method == http.MethodHead) {
+		return
+	}
+	var (
+		chain    []Decompressor
+		accepted map[string]bool
+	)
+	if res.Request != nil {
+		accepted = acceptedEncodings(res.Request.Header.Get("Accept-Encoding"))
+	}
+	for _, value := range res.Header.Values("Content-Encoding") {
+		for _, encoding := range strings.Split(value, ",") {
+			encoding = normalizeEncoding(encoding)
+			if encoding == "" || encoding == "identity" {
+				continue
+			}
+			if accepted != nil && !accepted[encoding] {
+				return
+			}
+			d, ok := GetDecompressor(encoding)
+			if !ok {
+				return
+			}
+			chain = append(chain, d)
+		}
+	}
+	if len(chain) == 0 {
+		return
+	}
+	res.Body = &decodeBody{body: res.Body, chain: chain, limit: limit}
+	res.Header.Del("Content-Encoding")
+	res.Header.Del("Content-Length")
+	res.ContentLength = -1
+	res.Uncompressed = true
+}
+
+// decodeBody creates the decoders lazily on the first read,
+// the invalid content error is returned by the Read.
+type decodeBody struct {
+	body        io.ReadCloser
+	chain       []Decompressor
+	r           io.Reader
+	closers     []io.Closer
+	err         error
+	limit, read int64
+}
+
+func (b *decodeBody) Read(p []byte) (int, error) {
+	if b.r == nil && b.err == nil {
+		b.r = b.body
+		for i := len(b.chain) - 1; i >= 0; i-- {
+			rc, err := b.chain[i](b.r)
+			if err != nil {
+				b.err = err
+				break
+			}
+			b.closers = append(b.closers, rc)
+			b.r = rc
+		}
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	if b.limit > 0 && int64(len(p)) > b.limit-b.read+1 {
+		// read one more byte to detect the decoded body exceeds the limit
+		p = p[:b.limit-b.read+1]
+	}
+	n, err := b.r.Read(p)
+	b.read += int64(n)
+	if b.limit > 0 && b.read > b.limit {
+		b.err = fmt.Errorf("%w: decoded body exceeds %d bytes", ErrBodyTooLarge, b.limit)
+		return n - int(b.read-b.limit), b.err
+	}
+	return n, err
+}
+
+func (b *decodeBody) Close() error {
+	for _, c := range b.closers {
+		_ = c.Close()
+	}
+	return b.body.Close()
+}
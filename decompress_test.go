@@ -0,0 +1,283 @@
+package ski
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecompress(t *testing.T) {
+	t.Parallel()
+	const content = "decompressed content"
+	encode := map[string]func(io.Writer) io.WriteCloser{
+		"gzip": func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		"zlib": func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) },
+		"zstd": func(w io.Writer) io.WriteCloser {
+			zw, _ := zstd.NewWriter(w)
+			return zw
+		},
+		"flate": func(w io.Writer) io.WriteCloser {
+			fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+			return fw
+		},
+	}
+	compress := func(data []byte, codecs ...string) []byte {
+		for _, codec := range codecs {
+			buf := new(bytes.Buffer)
+			w := encode[codec](buf)
+			_, _ = w.Write(data)
+			_ = w.Close()
+			data = buf.Bytes()
+		}
+		return data
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Accept-Encoding", r.Header.Get("Accept-Encoding"))
+		body := []byte(content)
+		switch r.URL.Path {
+		case "/gzip":
+			w.Header().Set("Content-Encoding", "gzip")
+			body = compress(body, "gzip")
+		case "/x-gzip":
+			w.Header().Set("Content-Encoding", "X-Gzip")
+			body = compress(body, "gzip")
+		case "/deflate":
+			w.Header().Set("Content-Encoding", "deflate")
+			body = compress(body, "zlib")
+		case "/raw-deflate":
+			w.Header().Set("Content-Encoding", "deflate")
+			body = compress(body, "flate")
+		case "/zstd":
+			w.Header().Set("Content-Encoding", "zstd")
+			body = compress(body, "zstd")
+		case "/multiple":
+			w.Header().Set("Content-Encoding", "deflate, identity, gzip")
+			body = compress(body, "zlib", "gzip")
+		case "/unknown":
+			w.Header().Set("Content-Encoding", "gzip, unknown")
+		case "/invalid":
+			w.Header().Set("Content-Encoding", "gzip")
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(ts.Close)
+
+	fetcher := NewFetcher(FetchOptions{})
+	get := func(path string, header ...string) (*http.Response, string, error) {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		for i := 0; i < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		res, err := fetcher.Do(req)
+		if err != nil {
+			return nil, "", err
+		}
+		defer res.Body.Close()
+		data, err := io.ReadAll(res.Body)
+		return res, string(data), err
+	}
+
+	for _, path := range []string{"/gzip", "/x-gzip", "/deflate", "/raw-deflate", "/zstd", "/multiple"} {
+		res, body, err := get(path)
+		if assert.NoError(t, err, path) {
+			assert.Equal(t, content, body, path)
+			assert.Empty(t, res.Header.Get("Content-Encoding"), path)
+			assert.True(t, res.Uncompressed, path)
+			assert.Equal(t, int64(-1), res.ContentLength, path)
+			assert.Subset(t, strings.Split(res.Header.Get("X-Accept-Encoding"), ", "), []string{"deflate", "gzip", "zstd"}, path)
+		}
+	}
+
+	res, body, err := get("/unknown")
+	if assert.NoError(t, err) {
+		assert.Equal(t, content, body)
+		assert.Equal(t, "gzip, unknown", res.Header.Get("Content-Encoding"))
+	}
+
+	res, body, err = get("/gzip", "Accept-Encoding", "gzip")
+	if assert.NoError(t, err) {
+		assert.Equal(t, content, body)
+		assert.Equal(t, "gzip", res.Header.Get("X-Accept-Encoding"))
+	}
+
+	_, _, err = get("/invalid")
+	assert.ErrorIs(t, err, gzip.ErrHeader)
+
+	req, _ := http.NewRequest(http.MethodHead, ts.URL+"/gzip", nil)
+	res, err = fetcher.Do(req)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "gzip", res.Header.Get("Content-Encoding"))
+		_ = res.Body.Close()
+	}
+}
+
+func TestDecompressZstdMaxBodySize(t *testing.T) {
+	t.Parallel()
+	content := bytes.Repeat([]byte("zstd "), 1024)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "zstd")
+		zw, _ := zstd.NewWriter(w)
+		_, _ = zw.Write(content)
+		_ = zw.Close()
+	}))
+	t.Cleanup(ts.Close)
+
+	read := func(maxBodySize int64) ([]byte, error) {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+		res, err := NewFetcher(FetchOptions{MaxBodySize: maxBodySize}).Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer res.Body.Close()
+		return ReadBody(res.Body)
+	}
+
+	// the compressed size is far less than the limit, but the decoded size exceeds
+	_, err := read(int64(len(content)) - 1)
+	assert.ErrorIs(t, err, ErrBodyTooLarge)
+
+	data, err := read(int64(len(content)))
+	if assert.NoError(t, err) {
+		assert.Equal(t, content, data)
+	}
+}
+
+func TestDecompressBomb(t *testing.T) {
+	t.Parallel()
+	const decoded, limit = 16 << 20, 1 << 20
+	bomb := new(bytes.Buffer)
+	gw, _ := gzip.NewWriterLevel(bomb, gzip.BestCompression)
+	_, _ = gw.Write(make([]byte, decoded))
+	_ = gw.Close()
+	assert.Less(t, bomb.Len(), limit/10)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		_, _ = w.Write(bomb.Bytes())
+	}))
+	t.Cleanup(ts.Close)
+
+	fetcher := NewFetcher(FetchOptions{MaxBodySize: limit})
+	get := func() *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+		res, err := fetcher.Do(req)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		t.Cleanup(func() { _ = res.Body.Close() })
+		return res
+	}
+
+	n, err := io.Copy(io.Discard, get().Body)
+	assert.ErrorIs(t, err, ErrBodyTooLarge)
+	assert.ErrorContains(t, err, "decoded body exceeds 1048576 bytes")
+	assert.Equal(t, int64(limit), n)
+
+	_, err = ReadBody(get().Body)
+	assert.ErrorIs(t, err, ErrBodyTooLarge)
+
+	res := get()
+	buf := make([]byte, limit+10)
+	n1, err := io.ReadFull(res.Body, buf[:limit])
+	if assert.NoError(t, err) {
+		assert.Equal(t, limit, n1)
+	}
+	n1, err = res.Body.Read(buf)
+	assert.Zero(t, n1)
+	assert.ErrorIs(t, err, ErrBodyTooLarge)
+}
+
+func TestAcceptEncoding(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Accept-Encoding", r.Header.Get("Accept-Encoding"))
+		// misbehaving server always compresses
+		w.Header().Set("Content-Encoding", "gzip")
+		gw := gzip.NewWriter(w)
+		_, _ = gw.Write([]byte("gzip content"))
+		_ = gw.Close()
+	}))
+	t.Cleanup(ts.Close)
+
+	get := func(opt FetchOptions, acceptEncoding string) (*http.Response, string) {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		res, err := NewFetcher(opt).Do(req)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		defer res.Body.Close()
+		data, err := io.ReadAll(res.Body)
+		assert.NoError(t, err)
+		return res, string(data)
+	}
+	gzipped := func(body string) bool { return strings.HasPrefix(body, "\x1f\x8b") }
+
+	res, body := get(FetchOptions{AcceptEncoding: []string{"identity"}}, "")
+	assert.Equal(t, "identity", res.Header.Get("X-Accept-Encoding"))
+	assert.Equal(t, "gzip", res.Header.Get("Content-Encoding"))
+	assert.True(t, gzipped(body))
+
+	res, body = get(FetchOptions{AcceptEncoding: []string{"zstd", "deflate"}}, "")
+	assert.Equal(t, "zstd, deflate", res.Header.Get("X-Accept-Encoding"))
+	assert.True(t, gzipped(body))
+
+	res, body = get(FetchOptions{AcceptEncoding: []string{"zstd", "gzip"}}, "")
+	assert.Equal(t, "zstd, gzip", res.Header.Get("X-Accept-Encoding"))
+	assert.Equal(t, "gzip content", body)
+
+	res, body = get(FetchOptions{AcceptEncoding: []string{"identity"}}, "x-gzip;q=0.5, br")
+	assert.Equal(t, "x-gzip;q=0.5, br", res.Header.Get("X-Accept-Encoding"))
+	assert.Equal(t, "gzip content", body)
+
+	for _, header := range []string{"identity", "gzip;q=0, deflate", "zstd"} {
+		_, body = get(FetchOptions{}, header)
+		assert.True(t, gzipped(body), header)
+	}
+	for _, header := range []string{"*", "GZIP", "deflate, gzip;q=1.0"} {
+		_, body = get(FetchOptions{}, header)
+		assert.Equal(t, "gzip content", body, header)
+	}
+}
+
+func TestRegisterDecompressor(t *testing.T) {
+	t.Parallel()
+	RegisterDecompressor("Base64", func(r io.Reader) (io.ReadCloser, error) {
+		return io.NopCloser(base64.NewDecoder(base64.StdEncoding, r)), nil
+	})
+	d, ok := GetDecompressor("base64")
+	assert.True(t, ok)
+	assert.NotNil(t, d)
+	assert.Contains(t, strings.Split(acceptEncoding(), ", "), "base64")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.Header.Get("Accept-Encoding"), "base64")
+		w.Header().Set("Content-Encoding", "base64")
+		_, _ = io.WriteString(w, base64.StdEncoding.EncodeToString([]byte("custom codec")))
+	}))
+	t.Cleanup(ts.Close)
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+	res, err := NewFetcher(FetchOptions{}).Do(req)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "custom codec", string(data))
+	}
+}
@@ -0,0 +1,89 @@
+package ski
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// dumpBody tees the response body into a buffer, the raw response
+// is written to the file asynchronously after the body is read to EOF or closed.
+type dumpBody struct {
+	io.ReadCloser
+	buf    bytes.Buffer
+	remain int64 // negative means unlimited
+	once   sync.Once
+	done   func([]byte)
+}
+
+func (b *dumpBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 && b.remain != 0 {
+		w := int64(n)
+		if b.remain > 0 && w > b.remain {
+			w = b.remain
+		}
+		b.buf.Write(p[:w])
+		if b.remain > 0 {
+			b.remain -= w
+		}
+	}
+	if errors.Is(err, io.EOF) {
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *dumpBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.finish()
+	return err
+}
+
+func (b *dumpBody) finish() { b.once.Do(func() { b.done(b.buf.Bytes()) }) }
+
+// dump wraps the response body to persist the raw response to the FetchOptions.DumpDir,
+// the file named by the URL hash and timestamp, eg: 3f2a...-1700000000000000000.http
+func (f *Fetcher) dump(res *http.Response) io.ReadCloser {
+	head := new(bytes.Buffer)
+	_, _ = fmt.Fprintf(head, "HTTP/%d.%d %s\r\n", res.ProtoMajor, res.ProtoMinor, res.Status)
+	_ = res.Header.Write(head)
+	head.WriteString("\r\n")
+
+	url := res.Request.URL.String()
+	sum := sha256.Sum256([]byte(url))
+	name := fmt.Sprintf("%s-%d.http", hex.EncodeToString(sum[:8]), time.Now().UnixNano())
+	logger := Logger(res.Request.Context())
+
+	remain := f.opt.MaxBodySize
+	if remain <= 0 {
+		remain = -1
+	}
+
+	return &dumpBody{
+		ReadCloser: res.Body,
+		remain:     remain,
+		done: func(body []byte) {
+			f.dumps.Add(1)
+			go func() {
+				defer f.dumps.Done()
+				data := append(head.Bytes(), body...)
+				if err := os.WriteFile(filepath.Join(f.opt.DumpDir, name), data, 0o644); err != nil {
+					logger.Error("dump response failed", slog.String("url", url), slog.Any("error", err))
+				}
+			}()
+		},
+	}
+}
+
+// Wait waits for the pending response dumps to be written.
+func (f *Fetcher) Wait() { f.dumps.Wait() }
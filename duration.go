@@ -0,0 +1,138 @@
+package ski
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// _duration parse the human duration string into the number of the unit, default is seconds.
+// Supports the compound units "2h 30m", "1 hour 30 minutes", "90 min",
+// the clock format "1:30:00", "04:05" and the ISO 8601 format "PT2H30M".
+//
+//	$duration: ms
+type _duration time.Duration
+
+func new_duration(args ...Executor) (Executor, error) {
+	if len(args) == 0 {
+		return _duration(time.Second), nil
+	}
+	unit := ExecToString(args[0])
+	if unit == "" {
+		return _duration(time.Second), nil
+	}
+	d, ok := durationUnit(unit)
+	if !ok {
+		return nil, fmt.Errorf("unknown duration unit %s", unit)
+	}
+	return _duration(d), nil
+}
+
+func (unit _duration) Exec(_ context.Context, arg any) (any, error) {
+	if arg == nil {
+		return nil, nil
+	}
+	str, err := cast.ToStringE(arg)
+	if err != nil {
+		return nil, err
+	}
+	d, err := ParseDuration(str)
+	if err != nil {
+		return nil, err
+	}
+	return float64(d) / float64(unit), nil
+}
+
+var (
+	durationPartRegexp  = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)\s*([a-zµμ]+)`)
+	durationClockRegexp = regexp.MustCompile(`^(?:(\d+):)?(\d{1,2}):(\d{1,2}(?:\.\d+)?)$`)
+	durationISORegexp   = regexp.MustCompile(`(?i)^P(?:(\d+(?:\.\d+)?)W)?(?:(\d+(?:\.\d+)?)D)?` +
+		`(?:T(?:(\d+(?:\.\d+)?)H)?(?:(\d+(?:\.\d+)?)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+)
+
+func durationUnit(unit string) (time.Duration, bool) {
+	switch strings.ToLower(unit) {
+	case "ns", "nanosecond", "nanoseconds":
+		return time.Nanosecond, true
+	case "us", "µs", "μs", "microsecond", "microseconds":
+		return time.Microsecond, true
+	case "ms", "msec", "msecs", "millisecond", "milliseconds":
+		return time.Millisecond, true
+	case "s", "sec", "secs", "second", "seconds":
+		return time.Second, true
+	case "m", "min", "mins", "minute", "minutes":
+		return time.Minute, true
+	case "h", "hr", "hrs", "hour", "hours":
+		return time.Hour, true
+	case "d", "day", "days":
+		return 24 * time.Hour, true
+	case "w", "wk", "wks", "week", "weeks":
+		return 7 * 24 * time.Hour, true
+	}
+	return 0, false
+}
+
+// ParseDuration parses the human duration string, see the _duration.
+func ParseDuration(str string) (time.Duration, error) {
+	str = strings.TrimSpace(str)
+	if str == "" {
+		return 0, fmt.Errorf("invalid duration %q", str)
+	}
+
+	if m := durationClockRegexp.FindStringSubmatch(str); m != nil {
+		var d float64
+		for i, unit := range []time.Duration{time.Hour, time.Minute, time.Second} {
+			if m[i+1] != "" {
+				n, _ := strconv.ParseFloat(m[i+1], 64)
+				d += n * float64(unit)
+			}
+		}
+		return time.Duration(d), nil
+	}
+
+	if m := durationISORegexp.FindStringSubmatch(str); m != nil {
+		// the designator without the components is invalid, eg: "P", "PT", "P1DT"
+		if strings.Join(m[1:], "") == "" || (strings.ContainsAny(str, "Tt") && strings.Join(m[3:], "") == "") {
+			return 0, fmt.Errorf("invalid duration %q", str)
+		}
+		var d float64
+		for i, unit := range []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second} {
+			if m[i+1] != "" {
+				n, _ := strconv.ParseFloat(m[i+1], 64)
+				d += n * float64(unit)
+			}
+		}
+		return time.Duration(d), nil
+	}
+
+	matches := durationPartRegexp.FindAllStringSubmatchIndex(str, -1)
+	if matches == nil {
+		return 0, fmt.Errorf("invalid duration %q", str)
+	}
+	var (
+		d    float64
+		last int
+	)
+	for _, m := range matches {
+		// only whitespace, commas and "and" are allowed between the parts
+		if sep := strings.TrimSpace(strings.ReplaceAll(str[last:m[0]], ",", "")); sep != "" && !strings.EqualFold(sep, "and") {
+			return 0, fmt.Errorf("invalid duration %q", str)
+		}
+		unit, ok := durationUnit(str[m[4]:m[5]])
+		if !ok {
+			return 0, fmt.Errorf("invalid duration %q: unknown unit %s", str, str[m[4]:m[5]])
+		}
+		n, _ := strconv.ParseFloat(str[m[2]:m[3]], 64)
+		d += n * float64(unit)
+		last = m[1]
+	}
+	if strings.TrimSpace(str[last:]) != "" {
+		return 0, fmt.Errorf("invalid duration %q", str)
+	}
+	return time.Duration(d), nil
+}
@@ -0,0 +1,77 @@
+package ski
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDuration(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		str  string
+		want time.Duration
+		err  string
+	}{
+		{"2h 30m", 2*time.Hour + 30*time.Minute, ""},
+		{"90 min", 90 * time.Minute, ""},
+		{"1 hour, 5 minutes and 10 seconds", time.Hour + 5*time.Minute + 10*time.Second, ""},
+		{"1.5h", 90 * time.Minute, ""},
+		{"2d4h", 52 * time.Hour, ""},
+		{"1:30:00", 90 * time.Minute, ""},
+		{"04:05", 4*time.Minute + 5*time.Second, ""},
+		{"PT2H30M", 2*time.Hour + 30*time.Minute, ""},
+		{"P1DT12H", 36 * time.Hour, ""},
+		{"250ms", 250 * time.Millisecond, ""},
+		{"", 0, "invalid duration"},
+		{"P", 0, "invalid duration"},
+		{"PT", 0, "invalid duration"},
+		{"P1DT", 0, "invalid duration"},
+		{"2 fortnights", 0, "unknown unit fortnights"},
+		{"about 2h", 0, "invalid duration"},
+		{"2h later", 0, "invalid duration"},
+	}
+	for _, c := range testCases {
+		t.Run(c.str, func(t *testing.T) {
+			d, err := ParseDuration(c.str)
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.want, d)
+			}
+		})
+	}
+}
+
+func TestDuration(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testCases := []struct {
+		schema string
+		arg    any
+		want   any
+	}{
+		{`$duration:`, "2h 30m", float64(9000)},
+		{`$duration: min`, "1:30:00", float64(90)},
+		{"$duration: ms\n$kind: int64", "1.5 seconds", int64(1500)},
+		{`$duration: h`, nil, nil},
+	}
+	for _, c := range testCases {
+		t.Run(c.schema, func(t *testing.T) {
+			exec, err := Compile(c.schema)
+			if !assert.NoError(t, err) {
+				return
+			}
+			v, err := exec.Exec(ctx, c.arg)
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.want, v)
+			}
+		})
+	}
+	_, err := Compile(`$duration: fortnight`)
+	assert.ErrorContains(t, err, "unknown duration unit fortnight")
+}
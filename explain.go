@@ -0,0 +1,97 @@
+package ski
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// WithExplain records the execution of each compiled executor to the Explainer
+// on the context, see WithExplainer. The executions are not recorded without the Explainer.
+func WithExplain() Option {
+	return func(c *compiler) { c.explain = true }
+}
+
+// ExplainStep the execution of an executor, the Path is the field path same as the FieldError,
+// empty on the top level. The steps of a field pipe are the intermediate values, the last
+// step is the final value of the field.
+type ExplainStep struct {
+	Path     string
+	Executor string
+	Line     int
+	Column   int
+	Result   any
+	Err      error
+}
+
+// Explainer collects the ExplainStep of the executions in one run,
+// so the schema is debugged without running the executors twice.
+type Explainer struct {
+	mu    sync.Mutex
+	steps []ExplainStep
+}
+
+// reserve returns the index of the step, the step is reserved before the execution
+// to keep the parent step before the nested steps.
+func (e *Explainer) reserve() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.steps = append(e.steps, ExplainStep{})
+	return len(e.steps) - 1
+}
+
+func (e *Explainer) set(i int, step ExplainStep) {
+	e.mu.Lock()
+	e.steps[i] = step
+	e.mu.Unlock()
+}
+
+// Steps returns the ExplainStep in the execution start order, the parent executor
+// is before the nested executors. The order of the concurrent map fields is not
+// deterministic, see WithConcurrency.
+func (e *Explainer) Steps() []ExplainStep {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.steps)
+}
+
+var explainerKey byte
+
+// WithExplainer returns a copy of parent context which collects the executions
+// of the Executor compiled with the WithExplain option.
+func WithExplainer(ctx context.Context) (context.Context, *Explainer) {
+	e := new(Explainer)
+	return WithValue(ctx, &explainerKey, e), e
+}
+
+// ExplainerFromContext returns the Explainer on context, nil if not set.
+func ExplainerFromContext(ctx context.Context) *Explainer {
+	e, _ := ctx.Value(&explainerKey).(*Explainer)
+	return e
+}
+
+// _explain the Executor records the execution to the Explainer
+type _explain struct {
+	Executor
+	name         string
+	line, column int
+}
+
+func (e _explain) Exec(ctx context.Context, arg any) (any, error) {
+	x := ExplainerFromContext(ctx)
+	if x == nil {
+		return e.Executor.Exec(ctx, arg)
+	}
+	i := x.reserve()
+	ret, err := e.Executor.Exec(ctx, arg)
+	path, _ := ctx.Value(&fieldPathKey).(string)
+	x.set(i, ExplainStep{
+		Path:     path,
+		Executor: e.name,
+		Line:     e.line,
+		Column:   e.column,
+		Result:   ret,
+		Err:      err,
+	})
+	return ret, err
+}
@@ -0,0 +1,73 @@
+package ski
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+	t.Parallel()
+	schema := `
+$json.parse:
+$map:
+  name:
+    $template: ${name}
+    $string.trim:
+  ids:
+    - $template: ${ids}
+    - $json.parse:
+    - $list:
+    - $each:
+        $kind: int`
+	arg := `{"name": " foo ", "ids": ["1", "2"]}`
+	exec, err := Compile(schema, WithExplain())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ctx, explainer := WithExplainer(context.Background())
+	v, err := exec.Exec(ctx, arg)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ids := _iter[any]{int32(1), int32(2)}
+	assert.Equal(t, map[string]any{"name": "foo", "ids": ids}, v)
+	assert.Equal(t, []ExplainStep{
+		{Path: "", Executor: "json.parse", Line: 2, Column: 1,
+			Result: map[string]any{"name": " foo ", "ids": []any{"1", "2"}}},
+		{Path: "", Executor: "map", Line: 3, Column: 1, Result: v},
+		{Path: "name", Executor: "template", Line: 5, Column: 5, Result: " foo "},
+		{Path: "name", Executor: "string.trim", Line: 6, Column: 5, Result: "foo"},
+		{Path: "ids", Executor: "template", Line: 8, Column: 7, Result: `["1","2"]`},
+		{Path: "ids", Executor: "json.parse", Line: 9, Column: 7, Result: []any{"1", "2"}},
+		{Path: "ids", Executor: "list", Line: 10, Column: 7, Result: _iter[any]{"1", "2"}},
+		{Path: "ids", Executor: "each", Line: 11, Column: 7, Result: ids},
+		{Path: "ids.0", Executor: "kind", Line: 12, Column: 9, Result: int32(1)},
+		{Path: "ids.1", Executor: "kind", Line: 12, Column: 9, Result: int32(2)},
+	}, explainer.Steps())
+
+	exec, err = Compile(`
+$map:
+  id:
+    $kind: int`, WithExplain())
+	if assert.NoError(t, err) {
+		ctx, explainer = WithExplainer(context.Background())
+		_, err = exec.Exec(ctx, "foo")
+		assert.NoError(t, err)
+		steps := explainer.Steps()
+		if assert.Len(t, steps, 2) {
+			assert.Equal(t, "id", steps[1].Path)
+			assert.ErrorContains(t, steps[1].Err, "unable to cast")
+		}
+	}
+
+	exec, err = Compile(schema)
+	if assert.NoError(t, err) {
+		ctx, explainer = WithExplainer(context.Background())
+		_, err = exec.Exec(ctx, arg)
+		assert.NoError(t, err)
+		assert.Empty(t, explainer.Steps())
+	}
+}
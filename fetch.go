@@ -1,11 +1,23 @@
 package ski
 
 import (
+	"cmp"
 	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Fetch http client interface
@@ -16,25 +28,513 @@ type Fetch interface {
 	Do(*http.Request) (*http.Response, error)
 }
 
+var fetchKey byte
+
+// WithFetch returns a copy of parent context with the Fetch, which is used by
+// the executors sending the requests, eg: $paginate.
+func WithFetch(ctx context.Context, fetch Fetch) context.Context {
+	return WithValue(ctx, &fetchKey, fetch)
+}
+
+// FetchFromContext returns the Fetch on context, nil if not set.
+func FetchFromContext(ctx context.Context) Fetch {
+	f, _ := ctx.Value(&fetchKey).(Fetch)
+	return f
+}
+
 // NewFetch return the http.Client implementation
 func NewFetch() Fetch {
 	return &http.Client{
-		Transport: &http.Transport{
-			Proxy: ProxyFromRequest,
-			DialContext: (&net.Dialer{
-				Timeout:   30 * time.Second,
-				KeepAlive: 30 * time.Second,
-			}).DialContext,
-			ForceAttemptHTTP2:     true,
-			MaxIdleConns:          100,
-			IdleConnTimeout:       90 * time.Second,
-			TLSHandshakeTimeout:   10 * time.Second,
-			ExpectContinueTimeout: 1 * time.Second,
+		Transport:     NewTransport(FetchOptions{}),
+		CheckRedirect: CheckRedirect,
+		Jar:           NewCookieJar(),
+	}
+}
+
+// NewTransport returns a new http.Transport with the FetchOptions,
+// it can be shared across Fetchers with FetchOptions.Transport to reuse connections.
+func NewTransport(opt FetchOptions) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	if opt.LocalAddr != nil {
+		dialer.LocalAddr = &net.TCPAddr{IP: opt.LocalAddr}
+	}
+	sessionCache := opt.TLSSessionCache
+	if sessionCache == nil && opt.TLSSessionCacheSize > 0 {
+		sessionCache = tls.NewLRUClientSessionCache(opt.TLSSessionCacheSize)
+	}
+	tlsConfig := opt.TLSConfig.Clone()
+	if tlsConfig == nil {
+		tlsConfig = new(tls.Config)
+	}
+	if tlsConfig.ClientSessionCache == nil {
+		tlsConfig.ClientSessionCache = sessionCache
+	}
+	if opt.InsecureSkipVerify {
+		tlsConfig.InsecureSkipVerify = true
+	}
+	if opt.TLSMinVersion != 0 {
+		tlsConfig.MinVersion = uint16(opt.TLSMinVersion)
+	}
+	transport := &http.Transport{
+		Proxy:           ProxyFromRequest,
+		TLSClientConfig: tlsConfig,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if ip := LocalAddrFromContext(ctx); ip != nil {
+				d := *dialer
+				d.LocalAddr = &net.TCPAddr{IP: ip}
+				return d.DialContext(ctx, network, addr)
+			}
+			return dialer.DialContext(ctx, network, addr)
 		},
-		Jar: NewCookieJar(),
+		ForceAttemptHTTP2:     !opt.ForceHTTP1,
+		MaxIdleConns:          cmp.Or(opt.MaxIdleConns, 100),
+		MaxIdleConnsPerHost:   cmp.Or(opt.MaxIdleConnsPerHost, 16),
+		MaxConnsPerHost:       opt.MaxConnsPerHost,
+		IdleConnTimeout:       cmp.Or(opt.IdleConnTimeout, 90*time.Second),
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
 	}
+	if opt.ForceHTTP1 {
+		// the non-nil empty map disables the HTTP/2 upgrade
+		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+	return transport
+}
+
+// TLSVersion the TLS version, the text form is "1.0", "1.1", "1.2" or "1.3",
+// the empty means the default.
+type TLSVersion uint16
+
+var tlsVersions = map[string]TLSVersion{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+func (v TLSVersion) MarshalText() ([]byte, error) {
+	if v == 0 {
+		return []byte{}, nil
+	}
+	for name, version := range tlsVersions {
+		if version == v {
+			return []byte(name), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown TLS version %#x", uint16(v))
 }
 
+func (v *TLSVersion) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*v = 0
+		return nil
+	}
+	version, ok := tlsVersions[strings.TrimPrefix(string(text), "TLS")]
+	if !ok {
+		return fmt.Errorf("unknown TLS version %s", text)
+	}
+	*v = version
+	return nil
+}
+
+var (
+	// ErrQuotaExceeded the Fetcher request quota has been used up
+	ErrQuotaExceeded = errors.New("request quota exceeded")
+	// ErrInvalidURL the request URL is rejected by the strict URL validation
+	ErrInvalidURL = errors.New("invalid request URL")
+	// ErrShortBody the response body is shorter than the declared Content-Length
+	ErrShortBody = errors.New("response body shorter than Content-Length")
+	// ErrBodyTooLarge the buffered response body exceeds the FetchOptions.MaxBodySize
+	ErrBodyTooLarge = errors.New("response body too large")
+	// ErrInterceptor the request is aborted by the FetchOptions.RequestInterceptors, it is not retried
+	ErrInterceptor = errors.New("request interceptor")
+)
+
+// RequestInterceptor modifies the outgoing request, see FetchOptions.RequestInterceptors
+type RequestInterceptor func(req *http.Request) error
+
+// ResponseInterceptor inspects or modifies the response, see FetchOptions.ResponseInterceptors
+type ResponseInterceptor func(res *http.Response) error
+
+// FetchOptions options
+type FetchOptions struct {
+	// MaxRequests the maximum number of requests the Fetcher will dispatch,
+	// zero means unlimited. Use Fetcher.ResetQuota to reset the counter.
+	MaxRequests int64 `yaml:"max-requests" json:"maxRequests"`
+	// LocalAddr the local IP address to bind the outbound connections,
+	// it can be overridden per request with WithLocalAddr. Ignored if the Transport is present.
+	LocalAddr net.IP `yaml:"local-addr" json:"localAddr"`
+	// MaxRedirects the maximum number of the HTTP redirects to follow, zero means the default 10,
+	// a negative value follows none. Exceeding returns the *RedirectsError, use the RedirectManual
+	// mode to return the redirect response instead.
+	MaxRedirects int `yaml:"max-redirects" json:"maxRedirects"`
+	// MaxMetaRefresh the maximum number of the HTML meta refresh and JS redirects
+	// to follow heuristically, zero means disabled.
+	MaxMetaRefresh int `yaml:"max-meta-refresh" json:"maxMetaRefresh"`
+	// DisableCharsetSniff if true the response charset is determined by the Content-Type
+	// header only, the body is not sniffed. It can be overridden per request with WithCharsetSniff.
+	DisableCharsetSniff bool `yaml:"disable-charset-sniff" json:"disableCharsetSniff"`
+	// MaxRetries the maximum number of retries on the network errors,
+	// 429 and 5xx responses, zero means no retry. The Retry-After header
+	// of the 429 and 503 responses is honored, capped at RetryBackoff.Max.
+	MaxRetries int `yaml:"max-retries" json:"maxRetries"`
+	// RetryBackoff the exponential backoff with jitter between retries.
+	RetryBackoff RetryBackoff `yaml:"retry-backoff" json:"retryBackoff"`
+	// Transport the shared base http.RoundTripper, if nil a new one is created by NewTransport.
+	// The shared transport must be created by NewTransport, the transport options of the Fetcher
+	// are ignored since they are applied by NewTransport: LocalAddr, TLSConfig, InsecureSkipVerify,
+	// TLSMinVersion, ForceHTTP1, TLSSessionCacheSize, TLSSessionCache and the connection pool options.
+	// The custom http.RoundTripper must resolve the proxy with ProxyFromRequest and dial with
+	// LocalAddrFromContext, otherwise ProxyRotator, WithProxyURL and WithLocalAddr have no effect.
+	// The Fetcher settings not related to the transport still apply.
+	Transport http.RoundTripper `yaml:"-" json:"-"`
+	// StrictContentLength if true, reading a response body shorter than
+	// the declared Content-Length returns ErrShortBody.
+	StrictContentLength bool `yaml:"strict-content-length" json:"strictContentLength"`
+	// MaxBodySize the maximum response body size in bytes to buffer, zero means unlimited.
+	// ReadBody returns ErrBodyTooLarge if exceeded, reading the response body as stream is not limited
+	// except the decompressed body, which fails once the decoded size exceeds.
+	MaxBodySize int64 `yaml:"max-body-size" json:"maxBodySize"`
+	// Headers the default headers of the requests, eg: "User-Agent", "Accept-Language".
+	// The header is set only if the request has not, so the request headers take precedence.
+	Headers map[string]string `yaml:"headers" json:"headers"`
+	// UserAgents the User-Agent pool rotated per request, it is applied when the request
+	// has no User-Agent and takes precedence over the Headers. See UserAgentFromResponse.
+	UserAgents []string `yaml:"user-agents" json:"userAgents"`
+	// UserAgentStrategy the User-Agent rotation strategy "round-robin" (default) or "random"
+	UserAgentStrategy string `yaml:"user-agent-strategy" json:"userAgentStrategy"`
+	// AcceptEncoding the encodings of the Accept-Encoding header if the request has not,
+	// default is the registered encodings, see RegisterDecompressor. The response is decompressed
+	// only if the encoding is accepted by the request, eg: "identity" leaves the body raw.
+	AcceptEncoding []string `yaml:"accept-encoding" json:"acceptEncoding"`
+	// Timing if true, collects the DNS, connect, TLS, TTFB and total durations
+	// of the requests, see TimingFromResponse.
+	Timing bool `yaml:"timing" json:"timing"`
+	// DumpDir if present, the raw responses are persisted to the directory asynchronously
+	// without blocking, the body is truncated to MaxBodySize. Use Fetcher.Wait to wait the pending writes.
+	DumpDir string `yaml:"dump-dir" json:"dumpDir"`
+	// TLSConfig the base TLS configuration of the transport, eg: the client certificates,
+	// the pinned root CAs. The TLS options below override the corresponding fields.
+	// The TLS options and ForceHTTP1 are ignored if the Transport is present.
+	TLSConfig *tls.Config `yaml:"-" json:"-"`
+	// InsecureSkipVerify if true, the server certificate is not verified.
+	InsecureSkipVerify bool `yaml:"insecure-skip-verify" json:"insecureSkipVerify"`
+	// TLSMinVersion the minimum TLS version, eg: "1.2", "1.3".
+	TLSMinVersion TLSVersion `yaml:"tls-min-version" json:"tlsMinVersion"`
+	// ForceHTTP1 if true, the HTTP/2 is disabled and the HTTP/1.1 is always used,
+	// the negotiated protocol is the http.Response.Proto.
+	ForceHTTP1 bool `yaml:"force-http1" json:"forceHTTP1"`
+	// MaxIdleConns the maximum number of the idle keep-alive connections across all hosts,
+	// default is 100. The connection pool options are ignored if the Transport is present.
+	MaxIdleConns int `yaml:"max-idle-conns" json:"maxIdleConns"`
+	// MaxIdleConnsPerHost the maximum number of the idle keep-alive connections per host,
+	// default is 16 rather than the http.DefaultMaxIdleConnsPerHost 2 for the concurrent scraping.
+	MaxIdleConnsPerHost int `yaml:"max-idle-conns-per-host" json:"maxIdleConnsPerHost"`
+	// MaxConnsPerHost the maximum number of the connections per host including the
+	// active ones, the requests exceed the limit wait, zero means unlimited.
+	MaxConnsPerHost int `yaml:"max-conns-per-host" json:"maxConnsPerHost"`
+	// IdleConnTimeout the duration the idle connection stays in the pool, default is 90 seconds.
+	IdleConnTimeout time.Duration `yaml:"idle-conn-timeout" json:"idleConnTimeout"`
+	// TLSSessionCacheSize the capacity of the LRU TLS session cache to resume the
+	// sessions on subsequent connections to the same host, zero means disabled.
+	TLSSessionCacheSize int `yaml:"tls-session-cache-size" json:"tlsSessionCacheSize"`
+	// TLSSessionCache the shared TLS session cache, it takes precedence over TLSSessionCacheSize.
+	TLSSessionCache tls.ClientSessionCache `yaml:"-" json:"-"`
+	// CircuitThreshold the number of consecutive failures (transport errors or 5xx responses)
+	// to open the per-host circuit breaker, zero means disabled. While the circuit is open,
+	// the requests to the host fail fast with ErrCircuitOpen until the CircuitCooldown elapsed,
+	// then a single probe request is allowed to close the circuit.
+	CircuitThreshold int `yaml:"circuit-threshold" json:"circuitThreshold"`
+	// CircuitCooldown the duration the circuit stays open, default is 30 seconds.
+	CircuitCooldown time.Duration `yaml:"circuit-cooldown" json:"circuitCooldown"`
+	// Cache if present, the GET responses are cached honoring the Cache-Control, Expires headers,
+	// the stale responses with ETag or Last-Modified are revalidated by the conditional requests.
+	// The response served from the cache has the XFromCache header.
+	Cache Cache `yaml:"-" json:"-"`
+	// ProxyRotator the proxy pool rotated per request, the proxy of the request
+	// specified by WithProxyURL takes precedence. See ProxyFromResponse.
+	// It requires the Transport to resolve the proxy with ProxyFromRequest.
+	ProxyRotator ProxyRotator `yaml:"proxy-rotator" json:"proxyRotator"`
+	// RateLimit the global and per-host requests per second, the requests
+	// block until allowed. The retries are also limited.
+	RateLimit RateLimit `yaml:"rate-limit" json:"rateLimit"`
+	// StrictURL if true, the request URL is validated by ValidateURL before dispatch.
+	StrictURL bool `yaml:"strict-url" json:"strictURL"`
+	// CheckRedirect the redirect policy after the request RedirectMode applied,
+	// if nil the redirect loop stops after MaxRedirects. See http.Client.CheckRedirect.
+	CheckRedirect func(req *http.Request, via []*http.Request) error `yaml:"-" json:"-"`
+	// CookieJar the cookie jar to persist the cookies across requests,
+	// if nil a new in-memory jar is created by NewCookieJar.
+	CookieJar http.CookieJar `yaml:"-" json:"-"`
+	// RequestInterceptors run in order before each attempt is sent, including the retries,
+	// to modify the outgoing request, eg: signing. The error aborts the request.
+	RequestInterceptors []RequestInterceptor `yaml:"-" json:"-"`
+	// ResponseInterceptors run in order on the response after the retries and the decompression,
+	// to inspect or modify it. The error closes the body and aborts the request.
+	ResponseInterceptors []ResponseInterceptor `yaml:"-" json:"-"`
+	// TracerProvider if present, a span per request will be emitted.
+	TracerProvider trace.TracerProvider `yaml:"-" json:"-"`
+}
+
+// Fetcher the Fetch implementation configured with FetchOptions
+type Fetcher struct {
+	client   *http.Client
+	opt      FetchOptions
+	requests atomic.Int64
+	tracer   trace.Tracer
+	dumps    sync.WaitGroup
+	breaker  *breaker
+	limiter  *limiter
+	proxies  *rotator[*url.URL]
+	agents   *rotator[string]
+}
+
+// NewFetcher returns a new Fetcher
+func NewFetcher(opt FetchOptions) *Fetcher {
+	transport := opt.Transport
+	if transport == nil {
+		transport = NewTransport(opt)
+	}
+	jar := opt.CookieJar
+	if jar == nil {
+		jar = NewCookieJar()
+	}
+	f := &Fetcher{
+		client: &http.Client{
+			Transport: transport,
+			Jar:       jar,
+		},
+		opt: opt,
+	}
+	f.client.CheckRedirect = f.checkRedirect
+	if opt.TracerProvider != nil {
+		f.tracer = opt.TracerProvider.Tracer(tracerName)
+	}
+	if opt.CircuitThreshold > 0 {
+		f.breaker = newBreaker(opt.CircuitThreshold, opt.CircuitCooldown)
+	}
+	f.limiter = newLimiter(opt.RateLimit)
+	f.proxies = newProxyRotator(opt.ProxyRotator)
+	f.agents = newRotator("user agent", opt.UserAgents, opt.UserAgentStrategy)
+	return f
+}
+
+// Do sends an HTTP request and returns an HTTP response.
+// If the quota has been used up, returns ErrQuotaExceeded without dispatching.
+func (f *Fetcher) Do(req *http.Request) (*http.Response, error) {
+	if f.opt.DisableCharsetSniff {
+		if _, ok := req.Context().Value(&charsetSniffKey).(bool); !ok {
+			req = req.WithContext(WithCharsetSniff(req.Context(), false))
+		}
+	}
+	res, err := f.do(req)
+	for i := 0; err == nil && i < f.opt.MaxMetaRefresh; i++ {
+		target := metaRefresh(res)
+		if target == nil {
+			break
+		}
+		_ = res.Body.Close()
+		req, err = http.NewRequestWithContext(req.Context(), http.MethodGet, target.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		// keep the redirect chain as the http.Client
+		req.Response = res
+		res, err = f.do(req)
+	}
+	if err == nil && f.opt.MaxBodySize > 0 {
+		res.Body = &limitBody{ReadCloser: res.Body, limit: f.opt.MaxBodySize}
+	}
+	return res, err
+}
+
+// defaultHeaders returns the clone of the request with the rotated User-Agent, the default
+// headers and the Accept-Encoding which the request has not, the request itself if nothing to set.
+func (f *Fetcher) defaultHeaders(req *http.Request) (*http.Request, error) {
+	cloned := false
+	set := func(key, value string) {
+		if !cloned {
+			cloned = true
+			req = req.Clone(req.Context())
+			if req.Header == nil {
+				req.Header = make(http.Header)
+			}
+		}
+		req.Header.Set(key, value)
+	}
+	if f.agents != nil && len(req.Header.Values("User-Agent")) == 0 {
+		agent, err := f.agents.pick()
+		if err != nil {
+			return nil, err
+		}
+		set("User-Agent", agent)
+	}
+	for key, value := range f.opt.Headers {
+		// the empty value is kept, eg: the empty User-Agent omits the header
+		if len(req.Header.Values(key)) == 0 {
+			set(key, value)
+		}
+	}
+	if req.Header.Get("Accept-Encoding") == "" && req.Header.Get("Range") == "" {
+		if len(f.opt.AcceptEncoding) > 0 {
+			set("Accept-Encoding", strings.Join(f.opt.AcceptEncoding, ", "))
+		} else {
+			set("Accept-Encoding", acceptEncoding())
+		}
+	}
+	return req, nil
+}
+
+func (f *Fetcher) do(req *http.Request) (*http.Response, error) {
+	var (
+		res *http.Response
+		err error
+	)
+	req, err = f.defaultHeaders(req)
+	if err != nil {
+		return nil, err
+	}
+	if f.opt.Cache != nil {
+		res, err = httpCache{f.opt.Cache, f.opt.MaxBodySize}.do(req, f.retry)
+	} else {
+		res, err = f.retry(req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if f.opt.StrictContentLength && res.ContentLength > 0 && req.Method != http.MethodHead {
+		res.Body = &lengthBody{ReadCloser: res.Body, expected: res.ContentLength}
+	}
+	decompress(res, f.opt.MaxBodySize)
+	if f.opt.DumpDir != "" {
+		res.Body = f.dump(res)
+	}
+	for _, intercept := range f.opt.ResponseInterceptors {
+		if err = intercept(res); err != nil {
+			_ = res.Body.Close()
+			return nil, fmt.Errorf("response interceptor: %w", err)
+		}
+	}
+	return res, nil
+}
+
+func (f *Fetcher) dispatch(req *http.Request) (*http.Response, error) {
+	if err := CheckDeadlineBudget(req.Context()); err != nil {
+		return nil, err
+	}
+	if len(f.opt.RequestInterceptors) > 0 {
+		// the interceptors modify the clone per attempt, the caller request is untouched
+		req = req.Clone(req.Context())
+		for _, intercept := range f.opt.RequestInterceptors {
+			if err := intercept(req); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInterceptor, err)
+			}
+		}
+	}
+	if f.opt.StrictURL {
+		if err := ValidateURL(req.URL); err != nil {
+			return nil, err
+		}
+	}
+	if f.proxies != nil && ProxyFromContext(req.Context()) == nil {
+		proxy, err := f.proxies.pick()
+		if err != nil {
+			return nil, err
+		}
+		// the rotated proxy is per attempt, must not be stored in the shared Context
+		req = req.WithContext(context.WithValue(req.Context(), &requestProxyKey, proxy))
+	}
+	if f.limiter != nil {
+		if err := f.limiter.wait(req.Context(), req.URL.Host, req.URL.Hostname()); err != nil {
+			return nil, err
+		}
+	}
+	if f.breaker != nil {
+		if err := f.breaker.allow(req.URL.Host); err != nil {
+			return nil, err
+		}
+	}
+	if f.opt.MaxRequests > 0 && f.requests.Add(1) > f.opt.MaxRequests {
+		if f.breaker != nil {
+			f.breaker.release(req.URL.Host)
+		}
+		return nil, ErrQuotaExceeded
+	}
+	var t *timing
+	if f.opt.Timing {
+		req, t = withTiming(req)
+	}
+	res, err := f.send(req)
+	if f.breaker != nil {
+		f.breaker.done(req.URL.Host, err, res)
+	}
+	if err == nil && t != nil {
+		res.Body = &timingBody{ReadCloser: res.Body, timing: t}
+	}
+	return res, err
+}
+
+func (f *Fetcher) send(req *http.Request) (*http.Response, error) {
+	if f.tracer == nil {
+		return f.client.Do(req)
+	}
+
+	ctx, span := f.tracer.Start(req.Context(), "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("url.full", req.URL.String()),
+		))
+	defer span.End()
+
+	res, err := f.client.Do(req.WithContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", res.StatusCode))
+	if res.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, res.Status)
+	}
+	return res, nil
+}
+
+// ValidateURL reports ErrInvalidURL if the URL is not absolute,
+// the scheme is not http or https, or the host is missing.
+func ValidateURL(u *url.URL) error {
+	switch {
+	case u == nil:
+		return fmt.Errorf("%w: URL is nil", ErrInvalidURL)
+	case !u.IsAbs():
+		return fmt.Errorf("%w: %q is not absolute", ErrInvalidURL, u)
+	case u.Scheme != "http" && u.Scheme != "https":
+		return fmt.Errorf("%w: unsupported scheme %q", ErrInvalidURL, u.Scheme)
+	case u.Hostname() == "":
+		return fmt.Errorf("%w: %q missing host", ErrInvalidURL, u)
+	}
+	return nil
+}
+
+func (f *Fetcher) checkRedirect(req *http.Request, via []*http.Request) error {
+	if f.opt.CheckRedirect == nil || RedirectModeFromContext(req.Context()) != RedirectFollow {
+		return checkRedirect(req, via, cmp.Or(f.opt.MaxRedirects, defaultMaxRedirects))
+	}
+	return f.opt.CheckRedirect(req, via)
+}
+
+// CookieJar returns the cookie jar of the Fetcher.
+func (f *Fetcher) CookieJar() http.CookieJar { return f.client.Jar }
+
+// Requests returns the number of requests counted against the quota.
+func (f *Fetcher) Requests() int64 { return f.requests.Load() }
+
+// ResetQuota resets the request counter.
+func (f *Fetcher) ResetQuota() { f.requests.Store(0) }
+
 var requestProxyKey byte
 
 // WithProxyURL returns a copy of parent context in which the proxy associated with context.
@@ -61,3 +561,119 @@ func ProxyFromContext(ctx context.Context) *url.URL {
 func ProxyFromRequest(req *http.Request) (*url.URL, error) {
 	return ProxyFromContext(req.Context()), nil
 }
+
+// lengthBody detects the body length mismatch with the declared Content-Length
+type lengthBody struct {
+	io.ReadCloser
+	expected, read int64
+}
+
+func (b *lengthBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if (errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)) && b.read < b.expected {
+		return n, fmt.Errorf("%w: read %d of %d bytes", ErrShortBody, b.read, b.expected)
+	}
+	return n, err
+}
+
+// limitBody carries the maximum size to buffer the response body
+type limitBody struct {
+	io.ReadCloser
+	limit int64
+}
+
+// ReadBody reads the whole response body, if the body is limited by the FetchOptions.MaxBodySize,
+// returns ErrBodyTooLarge when exceeded. Read the body directly to process the large body as stream.
+func ReadBody(body io.Reader) ([]byte, error) {
+	b, ok := body.(*limitBody)
+	if !ok {
+		return io.ReadAll(body)
+	}
+	data, err := io.ReadAll(io.LimitReader(b.ReadCloser, b.limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > b.limit {
+		return nil, fmt.Errorf("%w: exceeds %d bytes", ErrBodyTooLarge, b.limit)
+	}
+	return data, nil
+}
+
+var localAddrKey byte
+
+// WithLocalAddr returns a copy of parent context in which the local IP address
+// to bind the outbound connection associated with context.
+// Note that idle connections are reused regardless of the local address.
+func WithLocalAddr(ctx context.Context, ip net.IP) context.Context {
+	if ip == nil {
+		return ctx
+	}
+	return WithValue(ctx, &localAddrKey, ip)
+}
+
+// LocalAddrFromContext returns the local IP address on context.
+func LocalAddrFromContext(ctx context.Context) net.IP {
+	if ip := ctx.Value(&localAddrKey); ip != nil {
+		return ip.(net.IP)
+	}
+	return nil
+}
+
+var charsetSniffKey byte
+
+// WithCharsetSniff returns a copy of parent context in which whether to sniff the response charset from the body.
+func WithCharsetSniff(ctx context.Context, enabled bool) context.Context {
+	// the option is per request, do not set on the shared Context
+	return context.WithValue(ctx, &charsetSniffKey, enabled)
+}
+
+// CharsetSniffFromContext returns whether to sniff the response charset from the body on context, default is true.
+func CharsetSniffFromContext(ctx context.Context) bool {
+	if enabled, ok := ctx.Value(&charsetSniffKey).(bool); ok {
+		return enabled
+	}
+	return true
+}
+
+// ParseLink parses the RFC 5988 Link header values into a map keyed by rel.
+// eg: `<https://api.github.com/user/repos?page=3>; rel="next", <https://api.github.com/user/repos?page=50>; rel="last"`
+// If multiple links have the same rel, the first one wins.
+func ParseLink(header http.Header) map[string]string {
+	links := make(map[string]string)
+	for _, value := range header.Values("Link") {
+		for value != "" {
+			start := strings.IndexByte(value, '<')
+			if start < 0 {
+				break
+			}
+			end := strings.IndexByte(value[start:], '>')
+			if end < 0 {
+				break
+			}
+			target := value[start+1 : start+end]
+			value = value[start+end+1:]
+
+			var params string
+			if next := strings.IndexByte(value, '<'); next >= 0 {
+				params, value = value[:next], value[next:]
+			} else {
+				params, value = value, ""
+			}
+
+			for _, param := range strings.Split(params, ";") {
+				k, v, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if !ok || !strings.EqualFold(strings.TrimSpace(k), "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(v), `",`)) {
+					rel = strings.ToLower(rel)
+					if _, exists := links[rel]; !exists {
+						links[rel] = target
+					}
+				}
+			}
+		}
+	}
+	return links
+}
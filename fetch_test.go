@@ -0,0 +1,704 @@
+package ski
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestFetcherQuota(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, "ok")
+	}))
+	defer ts.Close()
+
+	fetch := NewFetcher(FetchOptions{MaxRequests: 3})
+	do := func() error {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+		res, err := fetch.Do(req)
+		if err != nil {
+			return err
+		}
+		return res.Body.Close()
+	}
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, do())
+	}
+	assert.ErrorIs(t, do(), ErrQuotaExceeded)
+
+	fetch.ResetQuota()
+	assert.NoError(t, do())
+}
+
+func TestParseLink(t *testing.T) {
+	t.Parallel()
+	header := make(http.Header)
+	header.Add("Link", `<https://api.github.com/user/repos?page=3&per_page=100>; rel="next", `+
+		`<https://api.github.com/user/repos?page=50&per_page=100>; rel="last"`)
+	header.Add("Link", `<https://api.github.com/user/repos?page=1>; rel="first prev"`)
+	header.Add("Link", `<https://api.github.com/user/repos?page=2>; REL=Next, <https://api.github.com/search>; Rel=search`)
+
+	assert.Equal(t, map[string]string{
+		"next":   "https://api.github.com/user/repos?page=3&per_page=100",
+		"last":   "https://api.github.com/user/repos?page=50&per_page=100",
+		"first":  "https://api.github.com/user/repos?page=1",
+		"prev":   "https://api.github.com/user/repos?page=1",
+		"search": "https://api.github.com/search",
+	}, ParseLink(header))
+	assert.Empty(t, ParseLink(make(http.Header)))
+}
+
+func TestFetcherLocalAddr(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Connection", "close")
+		host, _, _ := net.SplitHostPort(r.RemoteAddr)
+		_, _ = fmt.Fprint(w, host)
+	}))
+	defer ts.Close()
+
+	fetch := NewFetcher(FetchOptions{LocalAddr: net.ParseIP("127.0.0.1")})
+	do := func(ctx context.Context) string {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, nil)
+		res, err := fetch.Do(req)
+		if !assert.NoError(t, err) {
+			return ""
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		return string(body)
+	}
+
+	assert.Equal(t, "127.0.0.1", do(context.Background()))
+	assert.Equal(t, "127.0.0.2", do(WithLocalAddr(context.Background(), net.ParseIP("127.0.0.2"))))
+}
+
+func TestFetcherSharedTransport(t *testing.T) {
+	t.Parallel()
+	var conns atomic.Int32
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, "ok")
+	}))
+	ts.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	base := NewTransport(FetchOptions{})
+	a := NewFetcher(FetchOptions{Transport: base, MaxRequests: 2})
+	b := NewFetcher(FetchOptions{Transport: base})
+
+	for _, fetch := range []*Fetcher{a, b, a, b} {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+		res, err := fetch.Do(req)
+		if assert.NoError(t, err) {
+			_, _ = io.Copy(io.Discard, res.Body)
+			_ = res.Body.Close()
+		}
+	}
+	assert.Equal(t, int32(1), conns.Load())
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+	_, err := a.Do(req)
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+}
+
+func TestFetcherStrictContentLength(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Length", "10")
+		_, _ = fmt.Fprint(w, "short")
+	}))
+	defer ts.Close()
+
+	read := func(fetch Fetch) error {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+		res, err := fetch.Do(req)
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+		_, err = io.ReadAll(res.Body)
+		return err
+	}
+
+	err := read(NewFetcher(FetchOptions{StrictContentLength: true}))
+	assert.ErrorIs(t, err, ErrShortBody)
+	assert.ErrorContains(t, err, "read 5 of 10 bytes")
+
+	err = read(NewFetcher(FetchOptions{}))
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrShortBody)
+}
+
+func TestFetcherMaxBodySize(t *testing.T) {
+	t.Parallel()
+	const size = 4 << 20
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(bytes.Repeat([]byte("a"), size))
+	}))
+	defer ts.Close()
+
+	fetch := NewFetcher(FetchOptions{MaxBodySize: 1 << 20})
+	get := func() *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+		res, err := fetch.Do(req)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return res
+	}
+
+	res := get()
+	_, err := ReadBody(res.Body)
+	assert.ErrorIs(t, err, ErrBodyTooLarge)
+	_ = res.Body.Close()
+
+	res = get()
+	n, err := io.Copy(io.Discard, res.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(size), n)
+	_ = res.Body.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+	res, err = NewFetcher(FetchOptions{}).Do(req)
+	if assert.NoError(t, err) {
+		data, err := ReadBody(res.Body)
+		assert.NoError(t, err)
+		assert.Len(t, data, size)
+		_ = res.Body.Close()
+	}
+}
+
+func TestFetcherDeadlineBudget(t *testing.T) {
+	t.Parallel()
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		time.Sleep(30 * time.Millisecond)
+	}))
+	defer ts.Close()
+
+	fetch := NewFetcher(FetchOptions{})
+	ctx := WithDeadlineBudget(context.Background(), 50*time.Millisecond)
+	var err error
+	for i := 0; i < 5 && err == nil; i++ {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, nil)
+		var res *http.Response
+		if res, err = fetch.Do(req); err == nil {
+			_ = res.Body.Close()
+		}
+	}
+	assert.ErrorIs(t, err, ErrDeadlineExceeded)
+	assert.Less(t, requests.Load(), int32(5))
+}
+
+func TestFetcherDump(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Path", r.URL.Path)
+		_, _ = fmt.Fprint(w, "body of "+r.URL.Path)
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	fetch := NewFetcher(FetchOptions{DumpDir: dir, MaxBodySize: 9})
+	for _, path := range []string{"/a", "/b"} {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		res, err := fetch.Do(req)
+		if !assert.NoError(t, err) {
+			return
+		}
+		body, err := io.ReadAll(res.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "body of "+path, string(body))
+		assert.NoError(t, res.Body.Close())
+	}
+	fetch.Wait()
+
+	entries, err := os.ReadDir(dir)
+	if !assert.NoError(t, err) || !assert.Len(t, entries, 2) {
+		return
+	}
+	dumps := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		assert.True(t, strings.HasSuffix(entry.Name(), ".http"))
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		assert.NoError(t, err)
+		dumps = append(dumps, string(data))
+	}
+	slices.SortFunc(dumps, func(a, b string) int {
+		return strings.Compare(a[strings.Index(a, "X-Path"):], b[strings.Index(b, "X-Path"):])
+	})
+	for i, path := range []string{"/a", "/b"} {
+		assert.True(t, strings.HasPrefix(dumps[i], "HTTP/1.1 200 OK\r\n"))
+		assert.Contains(t, dumps[i], "X-Path: "+path+"\r\n")
+		assert.True(t, strings.HasSuffix(dumps[i], "\r\n\r\nbody of /"), dumps[i])
+	}
+}
+
+func TestFetcherStrictURL(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, "ok")
+	}))
+	defer ts.Close()
+
+	fetch := NewFetcher(FetchOptions{StrictURL: true})
+	testCases := []struct {
+		url, err string
+	}{
+		{ts.URL, ""},
+		{"/relative/path", `"/relative/path" is not absolute`},
+		{"ftp://example.com/file", `unsupported scheme "ftp"`},
+		{"http:///path", `"http:///path" missing host`},
+	}
+	for _, c := range testCases {
+		req, err := http.NewRequest(http.MethodGet, c.url, nil)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		res, err := fetch.Do(req)
+		if c.err == "" {
+			if assert.NoError(t, err) {
+				assert.NoError(t, res.Body.Close())
+			}
+			continue
+		}
+		assert.ErrorIs(t, err, ErrInvalidURL)
+		assert.ErrorContains(t, err, c.err)
+	}
+}
+
+func TestFetcherTLSSessionCache(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Connection", "close")
+		_, _ = fmt.Fprint(w, r.TLS.DidResume)
+	}))
+	defer ts.Close()
+
+	do := func(size int) []string {
+		transport := NewTransport(FetchOptions{TLSSessionCacheSize: size})
+		transport.TLSClientConfig.RootCAs = ts.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+		fetch := NewFetcher(FetchOptions{Transport: transport})
+		ret := make([]string, 0, 3)
+		for i := 0; i < 3; i++ {
+			req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+			res, err := fetch.Do(req)
+			if !assert.NoError(t, err) {
+				return nil
+			}
+			body, err := io.ReadAll(res.Body)
+			assert.NoError(t, err)
+			assert.NoError(t, res.Body.Close())
+			ret = append(ret, string(body))
+		}
+		return ret
+	}
+
+	assert.Equal(t, []string{"false", "true", "true"}, do(8))
+	assert.Equal(t, []string{"false", "false", "false"}, do(0))
+}
+
+func TestFetcherTLSConfig(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, tls.VersionName(r.TLS.Version))
+	}))
+	ts.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	ts.StartTLS()
+	defer ts.Close()
+
+	get := func(opt FetchOptions) (string, error) {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+		res, err := NewFetcher(opt).Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		return string(body), err
+	}
+
+	_, err := get(FetchOptions{})
+	assert.ErrorContains(t, err, "certificate signed by unknown authority")
+
+	body, err := get(FetchOptions{InsecureSkipVerify: true})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "TLS 1.2", body)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ts.Certificate())
+	body, err = get(FetchOptions{TLSConfig: &tls.Config{RootCAs: pool}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "TLS 1.2", body)
+	}
+
+	_, err = get(FetchOptions{TLSConfig: &tls.Config{RootCAs: x509.NewCertPool()}})
+	assert.ErrorContains(t, err, "certificate signed by unknown authority")
+
+	var opt FetchOptions
+	assert.NoError(t, yaml.Unmarshal([]byte(`{insecure-skip-verify: true, tls-min-version: "1.3"}`), &opt))
+	assert.Equal(t, TLSVersion(tls.VersionTLS13), opt.TLSMinVersion)
+	_, err = get(opt)
+	assert.ErrorContains(t, err, "protocol version not supported")
+
+	assert.ErrorContains(t, yaml.Unmarshal([]byte(`tls-min-version: "2.0"`), &opt), "unknown TLS version 2.0")
+	data, err := json.Marshal(FetchOptions{TLSMinVersion: tls.VersionTLS12})
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(data), `"tlsMinVersion":"1.2"`)
+	}
+}
+
+func TestFetcherHeaders(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "%s|%s|%s", r.Header.Get("User-Agent"),
+			r.Header.Get("Accept-Language"), r.Header.Get("Accept-Encoding"))
+	}))
+	defer ts.Close()
+
+	fetch := NewFetcher(FetchOptions{Headers: map[string]string{
+		"user-agent":      "ski/1.0",
+		"Accept-Language": "en-US",
+		"Accept-Encoding": "identity",
+	}})
+	get := func(fetch Fetch, header http.Header) string {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		res, err := fetch.Do(req)
+		if !assert.NoError(t, err) {
+			return ""
+		}
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		assert.NoError(t, err)
+		if header == nil {
+			assert.Empty(t, req.Header.Get("Accept-Language"), "the request is not modified")
+		}
+		return string(body)
+	}
+
+	assert.Equal(t, "ski/1.0|en-US|identity", get(fetch, nil))
+	assert.Equal(t, "custom|zh-CN|identity", get(fetch, http.Header{
+		"User-Agent":      {"custom"},
+		"Accept-Language": {"zh-CN"},
+	}))
+	assert.Equal(t, "|en-US|identity", get(fetch, http.Header{"User-Agent": {""}}))
+	assert.Contains(t, get(NewFetcher(FetchOptions{}), nil), "Go-http-client/1.1||deflate, gzip")
+}
+
+func TestFetcherInterceptors(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Signature") != "signed:"+r.URL.Path {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = fmt.Fprint(w, r.Header.Get("X-Order"))
+	}))
+	defer ts.Close()
+
+	var statuses []int
+	fetch := NewFetcher(FetchOptions{
+		RequestInterceptors: []RequestInterceptor{
+			func(req *http.Request) error {
+				req.Header.Set("X-Order", "1")
+				return nil
+			},
+			func(req *http.Request) error {
+				req.Header.Set("X-Order", req.Header.Get("X-Order")+",2")
+				req.Header.Set("X-Signature", "signed:"+req.URL.Path)
+				return nil
+			},
+		},
+		ResponseInterceptors: []ResponseInterceptor{
+			func(res *http.Response) error {
+				statuses = append(statuses, res.StatusCode)
+				return nil
+			},
+			func(res *http.Response) error {
+				if res.StatusCode == http.StatusNotFound {
+					return errors.New("not found")
+				}
+				res.Header.Set("X-Intercepted", "1")
+				return nil
+			},
+		},
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/ok", nil)
+	res, err := fetch.Do(req)
+	if assert.NoError(t, err) {
+		body, _ := io.ReadAll(res.Body)
+		_ = res.Body.Close()
+		assert.Equal(t, "1,2", string(body))
+		assert.Equal(t, "1", res.Header.Get("X-Intercepted"))
+		assert.Empty(t, req.Header.Get("X-Signature"), "the request is not modified")
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, ts.URL+"/missing", nil)
+	_, err = fetch.Do(req)
+	assert.EqualError(t, err, "response interceptor: not found")
+	assert.Equal(t, []int{http.StatusOK, http.StatusNotFound}, statuses)
+
+	var sent bool
+	fetch = NewFetcher(FetchOptions{
+		RequestInterceptors: []RequestInterceptor{
+			func(*http.Request) error { return errors.New("no credentials") },
+			func(*http.Request) error {
+				sent = true
+				return nil
+			},
+		},
+	})
+	_, err = fetch.Do(req)
+	assert.EqualError(t, err, "request interceptor: no credentials")
+	assert.ErrorIs(t, err, ErrInterceptor)
+	assert.False(t, sent)
+
+	// the interceptor error aborts the request without retry, even the network error
+	var calls int
+	fetch = NewFetcher(FetchOptions{
+		MaxRetries:   3,
+		RetryBackoff: RetryBackoff{Base: time.Millisecond},
+		RequestInterceptors: []RequestInterceptor{
+			func(*http.Request) error {
+				calls++
+				return &net.DNSError{Err: "no such host", Name: "auth.example.com"}
+			},
+		},
+	})
+	_, err = fetch.Do(req)
+	assert.ErrorIs(t, err, ErrInterceptor)
+	assert.Equal(t, 1, calls)
+}
+
+func TestFetcherConnectionPool(t *testing.T) {
+	t.Parallel()
+	var conns atomic.Int32
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		_, _ = fmt.Fprint(w, "ok")
+	}))
+	ts.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	transport := NewTransport(FetchOptions{})
+	assert.Equal(t, 100, transport.MaxIdleConns)
+	assert.Equal(t, 16, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 0, transport.MaxConnsPerHost)
+	assert.Equal(t, 90*time.Second, transport.IdleConnTimeout)
+
+	fetch := NewFetcher(FetchOptions{MaxConnsPerHost: 2, IdleConnTimeout: time.Minute})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+			res, err := fetch.Do(req)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer res.Body.Close()
+			body, err := io.ReadAll(res.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, "ok", string(body))
+		}()
+	}
+	wg.Wait()
+	// the 8 requests reuse the keep-alive connections
+	assert.LessOrEqual(t, conns.Load(), int32(2))
+}
+
+func TestFetcherForceHTTP1(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, r.Proto)
+	}))
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	defer ts.Close()
+
+	get := func(opt FetchOptions) (string, string) {
+		opt.InsecureSkipVerify = true
+		req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+		res, err := NewFetcher(opt).Do(req)
+		if !assert.NoError(t, err) {
+			return "", ""
+		}
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		assert.NoError(t, err)
+		return res.Proto, string(body)
+	}
+
+	proto, body := get(FetchOptions{})
+	assert.Equal(t, "HTTP/2.0", proto)
+	assert.Equal(t, "HTTP/2.0", body)
+
+	proto, body = get(FetchOptions{ForceHTTP1: true})
+	assert.Equal(t, "HTTP/1.1", proto)
+	assert.Equal(t, "HTTP/1.1", body)
+}
+
+func TestFetcherCircuitBreaker(t *testing.T) {
+	t.Parallel()
+	var (
+		healthy atomic.Bool
+		hits    atomic.Int32
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	fetch := NewFetcher(FetchOptions{CircuitThreshold: 3, CircuitCooldown: 100 * time.Millisecond})
+	do := func() (int, error) {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+		res, err := fetch.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		return res.StatusCode, res.Body.Close()
+	}
+
+	for i := 0; i < 3; i++ {
+		status, err := do()
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+	}
+
+	// open: fail fast without hitting the server
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		_, err := do()
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+	}
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+	assert.Equal(t, int32(3), hits.Load())
+
+	// half-open: the failed probe opens the circuit again
+	time.Sleep(120 * time.Millisecond)
+	status, err := do()
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	_, err = do()
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+
+	// half-open: the successful probe closes the circuit
+	healthy.Store(true)
+	time.Sleep(120 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		status, err = do()
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+	}
+	assert.Equal(t, int32(7), hits.Load())
+}
+
+func TestFetcherCookieJar(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "foo", Path: "/"})
+			return
+		}
+		if cookie, err := r.Cookie("session"); err == nil {
+			_, _ = fmt.Fprint(w, cookie.Value)
+		}
+	}))
+	defer ts.Close()
+
+	do := func(fetch *Fetcher, path string) string {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		res, err := fetch.Do(req)
+		if !assert.NoError(t, err) {
+			return ""
+		}
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		assert.NoError(t, err)
+		return string(body)
+	}
+
+	fetch := NewFetcher(FetchOptions{})
+	assert.Equal(t, "", do(fetch, "/profile"))
+	do(fetch, "/login")
+	assert.Equal(t, "foo", do(fetch, "/profile"))
+
+	jar := NewCookieJar()
+	u, _ := url.Parse(ts.URL)
+	jar.SetCookies(u, []*http.Cookie{{Name: "session", Value: "bar", Path: "/"}})
+	shared := NewFetcher(FetchOptions{CookieJar: jar})
+	assert.Equal(t, jar, shared.CookieJar())
+	assert.Equal(t, "bar", do(shared, "/profile"))
+	do(shared, "/login")
+	assert.Equal(t, "foo", jar.Cookies(u)[0].Value)
+}
+
+func TestFetcherCharsetSniff(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, "ok")
+	}))
+	defer ts.Close()
+
+	do := func(fetch *Fetcher, ctx context.Context) bool {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, nil)
+		res, err := fetch.Do(req)
+		if !assert.NoError(t, err) {
+			return false
+		}
+		defer res.Body.Close()
+		return CharsetSniffFromContext(res.Request.Context())
+	}
+
+	assert.True(t, do(NewFetcher(FetchOptions{}), context.Background()))
+
+	fetch := NewFetcher(FetchOptions{DisableCharsetSniff: true})
+	assert.False(t, do(fetch, context.Background()))
+	assert.True(t, do(fetch, WithCharsetSniff(context.Background(), true)))
+}
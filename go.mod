@@ -1,6 +1,6 @@
 module github.com/shiroyk/ski
 
-go 1.21
+go 1.22
 
 require (
 	github.com/PuerkitoBio/goquery v1.9.2
@@ -9,9 +9,13 @@ require (
 	github.com/antchfx/xpath v1.3.1
 	github.com/dlclark/regexp2 v1.11.2
 	github.com/grafana/sobek v0.0.0-20240711133011-3a280d337ef4
+	github.com/klauspost/compress v1.18.0
 	github.com/ohler55/ojg v1.23.0
 	github.com/spf13/cast v1.6.0
 	github.com/stretchr/testify v1.9.0
+	go.opentelemetry.io/otel v1.28.0
+	go.opentelemetry.io/otel/sdk v1.28.0
+	go.opentelemetry.io/otel/trace v1.28.0
 	golang.org/x/crypto v0.25.0
 	golang.org/x/net v0.27.0
 	gopkg.in/yaml.v3 v3.0.1
@@ -19,9 +23,14 @@ require (
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
 	github.com/go-sourcemap/sourcemap v2.1.4+incompatible // indirect
 	github.com/golang/groupcache v0.0.0-20210331224755-41bb18bfe9da // indirect
 	github.com/google/pprof v0.0.0-20240711041743-f6c9dda6c6da // indirect
+	github.com/google/uuid v1.6.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.opentelemetry.io/otel/metric v1.28.0 // indirect
+	golang.org/x/sys v0.22.0 // indirect
 	golang.org/x/text v0.16.0 // indirect
 )
@@ -1,10 +1,13 @@
 package gq
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"net/url"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
@@ -30,11 +33,15 @@ func builtins() FuncMap {
 		"text":    Text,
 		"next":    Next,
 		"slice":   Slice,
+		"eq":      Eq,
+		"first":   First,
+		"last":    Last,
 		"child":   Child,
 		"parent":  Parent,
 		"parents": Parents,
 		"prefix":  Prefix,
 		"suffix":  Suffix,
+		"srcset":  Srcset,
 	}
 }
 
@@ -235,6 +242,41 @@ func Slice(_ context.Context, content any, args ...string) (any, error) {
 	return nil, fmt.Errorf("slice: unexpected type %T", content)
 }
 
+// Eq reduces the set of matched elements to the one at the specified index.
+// If a negative index is given, it counts backwards starting at the end of the set.
+func Eq(_ context.Context, content any, args ...string) (any, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("eq(index) must have one int argument")
+	}
+	if node, ok := content.(*goquery.Selection); ok {
+		index, err := cast.ToIntE(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return node.Eq(index), nil
+	}
+
+	return nil, fmt.Errorf("eq: unexpected type %T", content)
+}
+
+// First reduces the set of matched elements to the first in the set.
+func First(_ context.Context, content any, _ ...string) (any, error) {
+	if node, ok := content.(*goquery.Selection); ok {
+		return node.First(), nil
+	}
+
+	return nil, fmt.Errorf("first: unexpected type %T", content)
+}
+
+// Last reduces the set of matched elements to the last in the set.
+func Last(_ context.Context, content any, _ ...string) (any, error) {
+	if node, ok := content.(*goquery.Selection); ok {
+		return node.Last(), nil
+	}
+
+	return nil, fmt.Errorf("last: unexpected type %T", content)
+}
+
 // Child gets the child elements of each element in the Selection.
 // If present the selector will return filtered by the specified selector.
 func Child(_ context.Context, content any, args ...string) (any, error) {
@@ -370,3 +412,127 @@ func Suffix(_ context.Context, content any, args ...string) (any, error) {
 		return content, nil
 	}
 }
+
+// srcsetCandidate the image candidate of srcset
+type srcsetCandidate struct {
+	url   string
+	width float64 // the width descriptor, e.g. 300w
+	dense float64 // the pixel density descriptor, e.g. 2x
+}
+
+// parseSrcset parses the srcset attribute into image candidates.
+func parseSrcset(srcset string) []srcsetCandidate {
+	var ret []srcsetCandidate
+	for s := srcset; ; {
+		s = strings.TrimLeft(s, " \t\n\r\f,")
+		if s == "" {
+			return ret
+		}
+		end := strings.IndexAny(s, " \t\n\r\f")
+		if end < 0 {
+			end = len(s)
+		}
+		c := srcsetCandidate{url: s[:end], dense: 1}
+		s = s[end:]
+		if strings.HasSuffix(c.url, ",") {
+			c.url = strings.TrimRight(c.url, ",")
+		} else {
+			var descriptors string
+			descriptors, s, _ = strings.Cut(s, ",")
+			for _, d := range strings.Fields(descriptors) {
+				v, err := strconv.ParseFloat(d[:len(d)-1], 64)
+				if err != nil {
+					continue
+				}
+				switch d[len(d)-1] {
+				case 'w':
+					c.width = v
+				case 'x':
+					c.dense = v
+				}
+			}
+		}
+		ret = append(ret, c)
+	}
+}
+
+// Srcset gets the image candidates URL of the srcset attribute, fallback to the src attribute.
+// The first argument is "best" (default) returns the highest resolution candidate,
+// or "all" returns all candidates from highest to lowest resolution.
+// If the second argument (or context baseURL) is present, the candidate URLs are resolved against it.
+func Srcset(ctx context.Context, content any, args ...string) (any, error) {
+	node, ok := content.(*goquery.Selection)
+	if !ok {
+		return nil, fmt.Errorf("srcset: unexpected content type %T", content)
+	}
+
+	mode := "best"
+	if len(args) > 0 && args[0] != "" {
+		mode = args[0]
+	}
+	if mode != "best" && mode != "all" {
+		return nil, fmt.Errorf("srcset(mode) `mode` must be best or all")
+	}
+
+	var base *url.URL
+	var baseStr string
+	if v, ok := ctx.Value("baseURL").(string); ok {
+		baseStr = v
+	} else if len(args) > 1 {
+		baseStr = args[1]
+	}
+	if baseStr != "" {
+		var err error
+		if base, err = url.Parse(baseStr); err != nil {
+			return nil, err
+		}
+	}
+
+	candidates := func(sel *goquery.Selection) ([]string, error) {
+		list := parseSrcset(sel.AttrOr("srcset", ""))
+		if len(list) == 0 {
+			if src, ok := sel.Attr("src"); ok {
+				list = append(list, srcsetCandidate{url: src, dense: 1})
+			}
+		}
+		slices.SortStableFunc(list, func(a, b srcsetCandidate) int {
+			if c := cmp.Compare(b.width, a.width); c != 0 {
+				return c
+			}
+			return cmp.Compare(b.dense, a.dense)
+		})
+		ret := make([]string, 0, len(list))
+		for _, c := range list {
+			if base == nil {
+				ret = append(ret, c.url)
+				continue
+			}
+			u, err := base.Parse(c.url)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, u.String())
+		}
+		return ret, nil
+	}
+
+	if mode == "all" {
+		var ret []string
+		for i := range node.Nodes {
+			urls, err := candidates(node.Eq(i))
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, urls...)
+		}
+		return ski.NewIterator(ret), nil
+	}
+
+	return contentToString(node, func(sel *goquery.Selection) (string, error) {
+		urls, err := candidates(sel)
+		if err != nil || len(urls) == 0 {
+			return "", err
+		}
+		return urls[0], nil
+	})
+}
@@ -134,3 +134,24 @@ func TestBuildInZip(t *testing.T) {
 		`<div id="n6" class="six odd row">6</div><div id="nf6" class="six odd row">f6</div>`,
 	})
 }
+
+func TestBuildInFuncSrcset(t *testing.T) {
+	t.Parallel()
+	assertError(t, `#images #i1 -> text -> srcset`, "unexpected content type string")
+
+	assertError(t, `#images img -> srcset(worst)`, "`mode` must be best or all")
+
+	assertValue(t, `#images #i1 -> srcset`, "/img/a-3x.png")
+
+	assertValue(t, `#images img -> srcset(best, https://localhost/page/)`, []string{
+		"https://localhost/img/a-3x.png",
+		"https://localhost/page/b-1080.png",
+		"https://localhost/img/c.png",
+	})
+
+	assertValue(t, `#images #i1 -> srcset(all, https://localhost/page/)`, []string{
+		"https://localhost/img/a-3x.png",
+		"https://localhost/page/img/a-2x.png",
+		"https://localhost/img/a-1x.png",
+	})
+}
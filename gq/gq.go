@@ -3,8 +3,10 @@ package gq
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"maps"
+	"strconv"
 	"strings"
 	"sync/atomic"
 
@@ -32,44 +34,75 @@ func SetFuncs(m FuncMap) {
 
 func new_value() ski.NewExecutor {
 	return ski.StringExecutor(func(str string) (ski.Executor, error) {
-		ret, err := compile(str)
-		if err != nil {
-			return nil, err
-		}
-		ret.calls = append(ret.calls, call{fn: value})
-		return ret, nil
+		return compileRule(str, value)
 	})
 }
 
 func new_element() ski.NewExecutor {
 	return ski.StringExecutor(func(str string) (ski.Executor, error) {
-		ret, err := compile(str)
-		if err != nil {
-			return nil, err
-		}
-		ret.calls = append(ret.calls, call{fn: element})
-		return ret, nil
+		return compileRule(str, element)
 	})
 }
 
 func new_elements() ski.NewExecutor {
 	return ski.StringExecutor(func(str string) (ski.Executor, error) {
-		ret, err := compile(str)
+		return compileRule(str, elements)
+	})
+}
+
+// compileRule compiles the rule with the fallback alternatives separated by "||",
+// the alternatives are evaluated left to right as the $first executor, the first
+// non-empty result is returned. The quoted alternative is the literal default.
+//
+//	.title @content || h1 || "untitled"
+func compileRule(raw string, fn Func) (ski.Executor, error) {
+	alternatives := splitAlternatives(raw)
+	execs := make([]ski.Executor, 0, len(alternatives))
+	for _, alt := range alternatives {
+		if lit, ok := literal(alt); ok {
+			execs = append(execs, ski.String(lit))
+			continue
+		}
+		ret, err := compile(alt)
 		if err != nil {
 			return nil, err
 		}
-		ret.calls = append(ret.calls, call{fn: elements})
-		return ret, nil
-	})
+		ret.calls = append(ret.calls, call{fn: fn})
+		execs = append(execs, ret)
+	}
+	if len(execs) == 1 {
+		return execs[0], nil
+	}
+	first, ok := ski.GetExecutor("first")
+	if !ok {
+		return nil, errors.New("executor first not found")
+	}
+	return first(execs...)
 }
 
+// literal returns the unquoted string of the single or double quoted alternative
+func literal(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || (s[0] != '"' && s[0] != '\'') || s[len(s)-1] != s[0] {
+		return "", false
+	}
+	if s[0] == '"' {
+		lit, err := strconv.Unquote(s)
+		return lit, err == nil
+	}
+	return s[1 : len(s)-1], true
+}
+
+// compile compiles the selector and the functions separated by "->" or "|", the trailing
+// "@name" of the selector or the function is the shorthand of the attr(name) function.
+// eg: "a.link @href", "a -> parent(li) @id"
 func compile(raw string) (ret matcher, err error) {
-	funcs := strings.Split(raw, "->")
-	if len(funcs) == 1 {
+	funcs := splitSteps(raw)
+	selector, attr := cutAttr(funcs[0])
+	if len(funcs) == 1 && attr == "" {
 		ret.Matcher, err = cascadia.Compile(funcs[0])
 		return
 	}
-	selector := strings.TrimSpace(funcs[0])
 	if len(selector) == 0 {
 		ret.Matcher = new(emptyMatcher)
 	} else {
@@ -79,27 +112,43 @@ func compile(raw string) (ret matcher, err error) {
 		}
 	}
 
-	ret.calls = make([]call, 0, len(funcs)-1)
+	ret.calls = make([]call, 0, len(funcs))
+	if attr != "" {
+		ret.calls = append(ret.calls, call{Attr, []string{attr}})
+	}
 
 	for _, function := range funcs[1:] {
-		function = strings.TrimSpace(function)
-		if function == "" {
-			continue
-		}
-		name, args, err := parseFuncArguments(function)
-		if err != nil {
-			return ret, err
+		function, attr = cutAttr(function)
+		if function != "" {
+			name, args, err := parseFuncArguments(function)
+			if err != nil {
+				return ret, err
+			}
+			fn, ok := buildInFuncs.Load().(FuncMap)[name]
+			if !ok {
+				return ret, fmt.Errorf("function %s not exists", name)
+			}
+			ret.calls = append(ret.calls, call{fn, args})
 		}
-		fn, ok := buildInFuncs.Load().(FuncMap)[name]
-		if !ok {
-			return ret, fmt.Errorf("function %s not exists", name)
+		if attr != "" {
+			ret.calls = append(ret.calls, call{Attr, []string{attr}})
 		}
-		ret.calls = append(ret.calls, call{fn, args})
 	}
 
 	return
 }
 
+// cutAttr returns the selector or the function without the trailing "@name" and the attribute name
+func cutAttr(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	i := strings.LastIndexAny(s, " \t\n")
+	name, ok := strings.CutPrefix(s[i+1:], "@")
+	if !ok || name == "" {
+		return s, ""
+	}
+	return strings.TrimSpace(s[:i+1]), name
+}
+
 type call struct {
 	fn   Func
 	args []string
@@ -197,7 +246,7 @@ func selection(content any) (*goquery.Selection, error) {
 		return goquery.NewDocumentFromNode(root).Selection, nil
 	case ski.Iterator:
 		if data.Len() == 0 {
-			return nil, nil
+			return new(goquery.Selection), nil
 		}
 		root := &html.Node{Type: html.DocumentNode}
 		doc := goquery.NewDocumentFromNode(root)
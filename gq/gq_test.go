@@ -44,6 +44,11 @@ var (
       <div id="nf4" class="four odd row">f4</div>
       <div id="nf5" class="five even row odder">f5</div>
       <div id="nf6" class="six odd row">f6</div>
+    </div>
+    <div id="images">
+      <img id="i1" src="/img/a.png" srcset="/img/a-1x.png, /img/a-3x.png 3x, img/a-2x.png 2x">
+      <img id="i2" src="/img/b.png" srcset="b-480.png 480w,b-1080.png 1080w, b-800.png 800w">
+      <img id="i3" src="/img/c.png">
     </div>
 	<script type="text/javascript">
 		(function() {
@@ -131,6 +136,72 @@ func TestValue(t *testing.T) {
 	assertValue(t, `script -> slice(0) -> attr(type)`, "text/javascript")
 }
 
+func TestAttrShorthand(t *testing.T) {
+	t.Parallel()
+	assertValue(t, `#a3 a @href`, "https://go.dev")
+
+	assertValue(t, `.body ul a @title`, []string{"Google page", "Github page", "Golang page", "Home page"})
+
+	assertValue(t, `.body ul li -> slice(1) @id`, "a2")
+
+	assertValue(t, `#images img:first-child @src`, "/img/a.png")
+
+	assertValue(t, `.body ul a -> parent(li) @id`, []string{"a1", "a2", "a3", "a4"})
+
+	assertElements(t, `#foot div @id`, []string{"nf1", "nf2", "nf3", "nf4", "nf5", "nf6"})
+
+	assertValue(t, `#a4 a @data-missing`, "")
+
+	_, err := new_value()(ski.String(`#a4 a -> unknown @id`))
+	assert.ErrorContains(t, err, "function unknown not exists")
+}
+
+func TestPipeline(t *testing.T) {
+	t.Parallel()
+	assertValue(t, `.body ul li | eq(2) | child(a) | attr(href)`, "https://go.dev")
+
+	assertValue(t, `.body ul a | eq(-1) @title`, "Home page")
+
+	assertValue(t, `#main .row | first | text`, "1")
+
+	assertValue(t, `#main .row -> last | text`, "6")
+
+	assertValue(t, `.body ul li[id|="a3"] | child(a) | text`, "Golang")
+
+	assertValue(t, `.body ul li | eq(0) | child(a) | attr(title) | prefix("Link: ")`, "Link: Google page")
+
+	assertValue(t, `.body ul a | eq(0) | attr(data-missing, "a | b")`, "a | b")
+
+	_, err := new_value()(ski.String(`.body ul li | nth(2)`))
+	assert.ErrorContains(t, err, "function nth not exists")
+
+	assertError(t, `.body ul li | eq(x)`, `unable to cast "x"`)
+}
+
+func TestFallback(t *testing.T) {
+	t.Parallel()
+	assertValue(t, `.missing @href || #a3 a @href`, "https://go.dev")
+
+	assertValue(t, `.missing || #a9 | text || "none"`, "none")
+
+	assertValue(t, `#n1 || "none"`, "1")
+
+	assertValue(t, `.missing @title || 'single || quoted'`, "single || quoted")
+
+	assertValue(t, `.missing || "say \"hi\""`, `say "hi"`)
+
+	assertValue(t, `#main .row | eq(9) || #foot .row | last`, "f6")
+
+	assertValue(t, `#a4 a @data-missing || #a4 a @title`, "Home page")
+
+	assertElement(t, `.missing || .body ul a | parents(li)`, `<li id="a1"><a href="https://google.com" title="Google page">Google</a></li>`)
+
+	assertError(t, `.missing | eq(x) || .missing | eq(y)`, `unable to cast "x"`)
+
+	_, err := new_value()(ski.String(`.missing || .body | unknown`))
+	assert.ErrorContains(t, err, "function unknown not exists")
+}
+
 func TestElement(t *testing.T) {
 	t.Parallel()
 	assertElement(t, `.body ul a -> parents(li)`, `<li id="a1"><a href="https://google.com" title="Google page">Google</a></li>`)
@@ -155,6 +226,24 @@ func TestElements(t *testing.T) {
 	assertElements(t, `#foot div -> slice(0, 3) -> text`, []string{"f1", "f2", "f3"})
 }
 
+func TestEmptyContent(t *testing.T) {
+	t.Parallel()
+	for _, init := range []ski.NewExecutor{new_value(), new_element(), new_elements()} {
+		exec, err := init(ski.String(`#main .row`))
+		if !assert.NoError(t, err) {
+			continue
+		}
+		for _, arg := range []any{nil, []string(nil), ski.NewIterator([]any{}), ski.NewIterator([]string{})} {
+			assert.NotPanics(t, func() {
+				v, err := exec.Exec(ctx, arg)
+				if assert.NoError(t, err) {
+					assert.Empty(t, v)
+				}
+			})
+		}
+	}
+}
+
 func TestNodeSelect(t *testing.T) {
 	t.Run("single", func(t *testing.T) {
 		exec, err := new_element()(ski.String(`script -> slice(0)`))
@@ -253,3 +342,18 @@ func TestExternalFunc(t *testing.T) {
 		}
 	}
 }
+
+func TestUnescapeJSON(t *testing.T) {
+	t.Parallel()
+	exec, err := ski.Compile(`
+$gq: div -> attr(data-props)
+$html.unescape:
+$json.parse:`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	v, err := exec.Exec(ctx, `<div data-props="{&amp;quot;id&amp;quot;:1,&amp;quot;name&amp;quot;:&amp;quot;a&amp;amp;b&amp;quot;}"></div>`)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]any{"id": float64(1), "name": "a&b"}, v)
+	}
+}
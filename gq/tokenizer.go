@@ -86,3 +86,85 @@ func parseFuncArguments(s string) (name string, args []string, err error) {
 
 	return
 }
+
+// splitSteps splits the rule into the selector and the function steps by the "->" or "|",
+// the separators inside the quotes, the brackets and the parentheses are ignored,
+// the "|=" attribute selector is not a separator.
+//
+//	div.item | eq(2) | attr(href)
+//	div.item -> eq(2) -> attr(href)
+func splitSteps(s string) []string {
+	return splitTopLevel(s, func(s string, i int) int {
+		switch {
+		case strings.HasPrefix(s[i:], "->"):
+			return 2
+		case s[i] == '|' && !strings.HasPrefix(s[i:], "|="):
+			return 1
+		default:
+			return 0
+		}
+	})
+}
+
+// splitAlternatives splits the rule into the fallback alternatives by the "||".
+//
+//	.title || h1 || "untitled"
+func splitAlternatives(s string) []string {
+	return splitTopLevel(s, func(s string, i int) int {
+		if strings.HasPrefix(s[i:], "||") {
+			return 2
+		}
+		return 0
+	})
+}
+
+// splitTopLevel splits the s at the separators outside the quotes, the brackets and the parentheses,
+// the match returns the length of the separator at the index, 0 if not a separator.
+func splitTopLevel(s string, match func(s string, i int) int) []string {
+	var (
+		parts []string
+		state = commonState
+		depth int
+		start int
+	)
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; ch {
+		case '\\':
+			i++
+			continue
+		case '\'':
+			if state == commonState {
+				state = singleQuoteState
+			} else if state == singleQuoteState {
+				state = commonState
+			}
+			continue
+		case '"':
+			if state == commonState {
+				state = doubleQuoteState
+			} else if state == doubleQuoteState {
+				state = commonState
+			}
+			continue
+		case '(', '[':
+			if state == commonState {
+				depth++
+			}
+			continue
+		case ')', ']':
+			if state == commonState && depth > 0 {
+				depth--
+			}
+			continue
+		}
+		if state != commonState || depth > 0 {
+			continue
+		}
+		if n := match(s, i); n > 0 {
+			parts = append(parts, s[start:i])
+			start = i + n
+			i += n - 1
+		}
+	}
+	return append(parts, s[start:])
+}
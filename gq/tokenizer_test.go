@@ -2,6 +2,8 @@ package gq
 
 import (
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestParseFuncArguments(t *testing.T) {
@@ -17,3 +19,38 @@ func TestParseFuncArguments(t *testing.T) {
 		}
 	}
 }
+
+func TestSplitSteps(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		rule string
+		want []string
+	}{
+		{`div.item`, []string{`div.item`}},
+		{`div.item | eq(2) | attr(href)`, []string{`div.item `, ` eq(2) `, ` attr(href)`}},
+		{`div.item -> eq(2)|attr(href)`, []string{`div.item `, ` eq(2)`, `attr(href)`}},
+		{`li[lang|="en"] | text`, []string{`li[lang|="en"] `, ` text`}},
+		{`a | prefix("->|") | suffix('|')`, []string{`a `, ` prefix("->|") `, ` suffix('|')`}},
+		{`a:not(.x|.y) | text`, []string{`a:not(.x|.y) `, ` text`}},
+		{`| text`, []string{``, ` text`}},
+	}
+	for _, c := range testCases {
+		assert.Equal(t, c.want, splitSteps(c.rule), c.rule)
+	}
+}
+
+func TestSplitAlternatives(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		rule string
+		want []string
+	}{
+		{`.title`, []string{`.title`}},
+		{`.title | text || h1 || "untitled"`, []string{`.title | text `, ` h1 `, ` "untitled"`}},
+		{`a | prefix("||") || 'a||b'`, []string{`a | prefix("||") `, ` 'a||b'`}},
+		{`li[lang|="en"] || li`, []string{`li[lang|="en"] `, ` li`}},
+	}
+	for _, c := range testCases {
+		assert.Equal(t, c.want, splitAlternatives(c.rule), c.rule)
+	}
+}
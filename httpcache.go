@@ -0,0 +1,191 @@
+package ski
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// XFromCache the header set on the response served from the FetchOptions.Cache
+const XFromCache = "X-From-Cache"
+
+// httpCacheKeyPrefix the key prefix of the responses in the Cache
+const httpCacheKeyPrefix = "ski:http:"
+
+// httpCache the client side cache of the GET responses, the freshness is determined by
+// the Cache-Control max-age or Expires header, the stale response with ETag or Last-Modified
+// is revalidated by the conditional request. The Vary header is not supported except "*".
+// The response body larger than the limit is not stored, zero means unlimited.
+type httpCache struct {
+	store Cache
+	limit int64
+}
+
+func (c httpCache) do(req *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" || hasCacheDirective(req.Header, "no-store") {
+		return next(req)
+	}
+	key := httpCacheKeyPrefix + req.URL.String()
+	cached, body, storedAt := c.load(req, key)
+	if cached != nil {
+		if !hasCacheDirective(req.Header, "no-cache") && isFresh(cached.Header, storedAt) {
+			cached.Header.Set(XFromCache, "1")
+			return cached, nil
+		}
+		req = req.Clone(req.Context())
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if modified := cached.Header.Get("Last-Modified"); modified != "" {
+			req.Header.Set("If-Modified-Since", modified)
+		}
+	}
+
+	res, err := next(req)
+	if err != nil {
+		return nil, err
+	}
+	if cached != nil && res.StatusCode == http.StatusNotModified {
+		_ = res.Body.Close()
+		for k, v := range res.Header {
+			cached.Header[k] = v
+		}
+		c.save(req, key, cached, body)
+		cached.Header.Set(XFromCache, "1")
+		cached.Body = io.NopCloser(bytes.NewReader(body))
+		return cached, nil
+	}
+	if !isCacheable(res) || (c.limit > 0 && res.ContentLength > c.limit) {
+		return res, nil
+	}
+
+	reader := io.Reader(res.Body)
+	if c.limit > 0 {
+		reader = io.LimitReader(res.Body, c.limit+1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		_ = res.Body.Close()
+		return nil, err
+	}
+	if c.limit > 0 && int64(len(data)) > c.limit {
+		// too large to store, the buffered part is returned with the rest of the body
+		res.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(data), res.Body), res.Body}
+		return res, nil
+	}
+	_ = res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(data))
+	c.save(req, key, res, data)
+	return res, nil
+}
+
+// load returns the cached response and the time stored, nil if not found or invalid
+func (c httpCache) load(req *http.Request, key string) (*http.Response, []byte, time.Time) {
+	data, err := c.store.Get(req.Context(), key)
+	if err != nil || len(data) == 0 {
+		return nil, nil, time.Time{}
+	}
+	stamp, raw, ok := bytes.Cut(data, []byte("\n"))
+	if !ok {
+		return nil, nil, time.Time{}
+	}
+	nano, err := strconv.ParseInt(string(stamp), 10, 64)
+	if err != nil {
+		return nil, nil, time.Time{}
+	}
+	res, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(raw)), req)
+	if err != nil {
+		return nil, nil, time.Time{}
+	}
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, nil, time.Time{}
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	return res, body, time.Unix(0, nano)
+}
+
+// save stores the response with the current time, the error is logged
+func (c httpCache) save(req *http.Request, key string, res *http.Response, body []byte) {
+	buf := new(bytes.Buffer)
+	_, _ = fmt.Fprintf(buf, "%d\nHTTP/1.1 %s\r\n", time.Now().UnixNano(), res.Status)
+	header := res.Header.Clone()
+	header.Del(XFromCache)
+	header.Del("Transfer-Encoding")
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	_ = header.Write(buf)
+	buf.WriteString("\r\n")
+	buf.Write(body)
+	if err := c.store.Set(req.Context(), key, buf.Bytes()); err != nil {
+		Logger(req.Context()).Warn("cache response failed", slog.String("url", req.URL.String()), slog.Any("error", err))
+	}
+}
+
+// cacheDirectives parses the Cache-Control header into the directives
+func cacheDirectives(header http.Header) map[string]string {
+	directives := make(map[string]string)
+	for _, part := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name != "" {
+			directives[strings.ToLower(name)] = strings.Trim(value, `"`)
+		}
+	}
+	return directives
+}
+
+func hasCacheDirective(header http.Header, name string) bool {
+	_, ok := cacheDirectives(header)[name]
+	if !ok && name == "no-cache" {
+		return header.Get("Pragma") == "no-cache"
+	}
+	return ok
+}
+
+// isCacheable reports whether the response can be stored, it must have
+// the freshness lifetime or the validators.
+func isCacheable(res *http.Response) bool {
+	if res.StatusCode != http.StatusOK || res.Header.Get("Vary") == "*" {
+		return false
+	}
+	directives := cacheDirectives(res.Header)
+	if _, ok := directives["no-store"]; ok {
+		return false
+	}
+	_, maxAge := directives["max-age"]
+	return maxAge || res.Header.Get("Expires") != "" ||
+		res.Header.Get("ETag") != "" || res.Header.Get("Last-Modified") != ""
+}
+
+// isFresh reports whether the age of the response is within the freshness lifetime
+func isFresh(header http.Header, storedAt time.Time) bool {
+	directives := cacheDirectives(header)
+	if _, ok := directives["no-cache"]; ok {
+		return false
+	}
+	age := time.Since(storedAt)
+	if v, err := strconv.Atoi(header.Get("Age")); err == nil && v > 0 {
+		age += time.Duration(v) * time.Second
+	}
+	if v, ok := directives["max-age"]; ok {
+		maxAge, err := strconv.Atoi(v)
+		return err == nil && age < time.Duration(maxAge)*time.Second
+	}
+	expires, err := http.ParseTime(header.Get("Expires"))
+	if err != nil {
+		return false
+	}
+	date, err := http.ParseTime(header.Get("Date"))
+	if err != nil {
+		date = storedAt
+	}
+	return age < expires.Sub(date)
+}
@@ -0,0 +1,148 @@
+package ski
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetcherCache(t *testing.T) {
+	t.Parallel()
+	var hits, notModified atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/ttl":
+			w.Header().Set("Cache-Control", "max-age=1")
+		case "/etag":
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				notModified.Add(1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/expires":
+			w.Header().Set("Expires", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+		case "/no-store":
+			w.Header().Set("Cache-Control", "no-store, max-age=60")
+		}
+		_, _ = io.WriteString(w, r.URL.Path)
+	}))
+	defer ts.Close()
+
+	fetch := NewFetcher(FetchOptions{Cache: NewCache()})
+	get := func(path string, header ...string) (string, bool) {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		for i := 0; i < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		res, err := fetch.Do(req)
+		if !assert.NoError(t, err) {
+			return "", false
+		}
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		body, err := io.ReadAll(res.Body)
+		assert.NoError(t, err)
+		return string(body), res.Header.Get(XFromCache) == "1"
+	}
+	assertGet := func(path string, fromCache bool, wantHits int32, header ...string) {
+		hits.Store(0)
+		body, cached := get(path, header...)
+		assert.Equal(t, path, body)
+		assert.Equal(t, fromCache, cached, path)
+		assert.Equal(t, wantHits, hits.Load(), path)
+	}
+
+	// cache hit
+	assertGet("/fresh", false, 1)
+	assertGet("/fresh", true, 0)
+	assertGet("/expires", false, 1)
+	assertGet("/expires", true, 0)
+	// the request directives bypass the cache
+	assertGet("/fresh", false, 1, "Cache-Control", "no-store")
+	assertGet("/fresh", false, 1, "Cache-Control", "no-cache")
+
+	// revalidation
+	assertGet("/etag", false, 1)
+	assertGet("/etag", true, 1)
+	assertGet("/etag", true, 1)
+	assert.Equal(t, int32(2), notModified.Load())
+
+	// TTL expiry
+	assertGet("/ttl", false, 1)
+	assertGet("/ttl", true, 0)
+	time.Sleep(1100 * time.Millisecond)
+	assertGet("/ttl", false, 1)
+
+	// not cacheable
+	assertGet("/no-store", false, 1)
+	assertGet("/no-store", false, 1)
+	assertGet("/plain", false, 1)
+	assertGet("/plain", false, 1)
+}
+
+func TestFetcherCacheLimit(t *testing.T) {
+	t.Parallel()
+	var hits atomic.Int32
+	large := strings.Repeat("a", 100)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		switch r.URL.Path {
+		case "/small":
+			_, _ = io.WriteString(w, "small")
+		case "/length":
+			w.Header().Set("Content-Length", strconv.Itoa(len(large)))
+			_, _ = io.WriteString(w, large)
+		case "/chunked":
+			_, _ = io.WriteString(w, large[:50])
+			w.(http.Flusher).Flush()
+			_, _ = io.WriteString(w, large[50:])
+		}
+	}))
+	defer ts.Close()
+
+	fetch := NewFetcher(FetchOptions{Cache: NewCache(), MaxBodySize: 10})
+	get := func(path string) (string, bool) {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		res, err := fetch.Do(req)
+		if !assert.NoError(t, err) {
+			return "", false
+		}
+		defer res.Body.Close()
+		// read directly as stream, the MaxBodySize only limits the buffered reads
+		body, err := io.ReadAll(res.Body)
+		assert.NoError(t, err)
+		return string(body), res.Header.Get(XFromCache) == "1"
+	}
+
+	for _, path := range []string{"/length", "/chunked"} {
+		hits.Store(0)
+		for i := 0; i < 2; i++ {
+			body, cached := get(path)
+			assert.Equal(t, large, body, path)
+			assert.False(t, cached, path)
+		}
+		assert.Equal(t, int32(2), hits.Load(), path)
+	}
+
+	hits.Store(0)
+	body, cached := get("/small")
+	assert.Equal(t, "small", body)
+	assert.False(t, cached)
+	body, cached = get("/small")
+	assert.Equal(t, "small", body)
+	assert.True(t, cached)
+	assert.Equal(t, int32(1), hits.Load())
+}
@@ -13,20 +13,49 @@ import (
 
 func init() {
 	ski.Register("jq", new_expr())
+	ski.Register("jq.element", new_element())
+	ski.Register("jq.elements", new_elements())
 }
 
 type expr struct {
 	jp.Expr
-	normal bool
+	ret func(jp.Expr, any) any
 }
 
+// new_expr returns the first value if the path is normal (no wildcard, filter or descent),
+// otherwise returns the array of the values.
 func new_expr() ski.NewExecutor {
 	return ski.StringExecutor(func(str string) (ski.Executor, error) {
 		x, err := jp.ParseString(str)
 		if err != nil {
 			return nil, err
 		}
-		return expr{x, x.Normal()}, nil
+		if x.Normal() {
+			return expr{x, element}, nil
+		}
+		return expr{x, value}, nil
+	})
+}
+
+// new_element always returns the first value, nil if not found.
+func new_element() ski.NewExecutor {
+	return ski.StringExecutor(func(str string) (ski.Executor, error) {
+		x, err := jp.ParseString(str)
+		if err != nil {
+			return nil, err
+		}
+		return expr{x, element}, nil
+	})
+}
+
+// new_elements always returns the Iterator of the values, nil if not found.
+func new_elements() ski.NewExecutor {
+	return ski.StringExecutor(func(str string) (ski.Executor, error) {
+		x, err := jp.ParseString(str)
+		if err != nil {
+			return nil, err
+		}
+		return expr{x, elements}, nil
 	})
 }
 
@@ -35,10 +64,19 @@ func (e expr) Exec(_ context.Context, arg any) (any, error) {
 	if err != nil {
 		return nil, err
 	}
-	if e.normal {
-		return e.First(obj), nil
+	return e.ret(e.Expr, obj), nil
+}
+
+func value(x jp.Expr, obj any) any { return x.Get(obj) }
+
+func element(x jp.Expr, obj any) any { return x.First(obj) }
+
+func elements(x jp.Expr, obj any) any {
+	values := x.Get(obj)
+	if len(values) == 0 {
+		return nil
 	}
-	return e.Get(obj), nil
+	return ski.NewIterator(values)
 }
 
 func doc(content any) (any, error) {
@@ -50,7 +50,11 @@ var (
 )
 
 func assertValue(t *testing.T, arg string, expected any) {
-	exec, err := new_expr()(ski.String(arg))
+	assertExec(t, new_expr(), arg, expected)
+}
+
+func assertExec(t *testing.T, init ski.NewExecutor, arg string, expected any) {
+	exec, err := init(ski.String(arg))
 	if assert.NoError(t, err) {
 		v, err := exec.Exec(context.Background(), content)
 		if assert.NoError(t, err) {
@@ -64,4 +68,47 @@ func TestGetString(t *testing.T) {
 	assertValue(t, `$.store.book[-1].price`, 22.99)
 	assertValue(t, `$.store.book[*].author`, []any{"Nigel Rees", "Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien"})
 	assertValue(t, `$.store.book[?(@.price < 10)].isbn`, []any{`0-553-21311-3`})
+	assertValue(t, `$.store.book[0].isbn`, nil)
+	assertValue(t, `$.store.book[*].missing`, []any(nil))
+}
+
+func TestElement(t *testing.T) {
+	t.Parallel()
+	assertExec(t, new_element(), `$.store.bicycle.color`, "red")
+	assertExec(t, new_element(), `$.store.book[*].title`, "Sayings of the Century")
+	assertExec(t, new_element(), `$.store.book[?(@.price > 20)].author`, "J. R. R. Tolkien")
+	assertExec(t, new_element(), `$.store.missing`, nil)
+}
+
+func TestElements(t *testing.T) {
+	t.Parallel()
+	assertExec(t, new_elements(), `$.store.book[*].category`, ski.NewIterator([]any{"reference", "fiction", "fiction", "fiction"}))
+	assertExec(t, new_elements(), `$.expensive`, ski.NewIterator([]any{int64(10)}))
+	assertExec(t, new_elements(), `$.store.book[*].missing`, nil)
+	assertExec(t, new_elements(), `$.missing`, nil)
+
+	exec, err := ski.Compile(`
+$jq.elements: $.store.book[?(@.price < 10)]
+$each:
+  $jq.element: $.title`)
+	if assert.NoError(t, err) {
+		v, err := exec.Exec(context.Background(), content)
+		if assert.NoError(t, err) {
+			assert.Equal(t, ski.NewIterator([]any{"Sayings of the Century", "Moby Dick"}), v)
+		}
+	}
+}
+
+func TestJSVar(t *testing.T) {
+	t.Parallel()
+	exec, err := ski.Compile(`
+$js.var: window.__DATA__
+$jq: $.user.name`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	v, err := exec.Exec(context.Background(), `<script>window.__DATA__ = {"user": {"name": "foo"}};</script>`)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "foo", v)
+	}
 }
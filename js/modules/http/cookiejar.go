@@ -74,7 +74,7 @@ func (j *CookieJar) Set(call sobek.FunctionCall, rt *sobek.Runtime) (ret sobek.V
 	case map[string]any:
 		cookies = append(cookies, toCookie(e))
 	case []any:
-		for _, cookie := range cookies {
+		for _, cookie := range e {
 			cookies = append(cookies, toCookie(cast.ToStringMap(cookie)))
 		}
 	default:
@@ -49,6 +49,9 @@ func TestCookie(t *testing.T) {
 		cookieJar.del(url);
 		const res2 = http.get(url);
 		assert.equal(res2.text(), "");
+		cookieJar.set(url, [{ name: "foo", value: "baz", path: "/" }, { name: "other", value: "1", path: "/" }]);
+		assert.equal(cookieJar.getAll({ url: url }).length, 2);
+		assert.equal(http.get(url).text(), "foo=baz");
 	`)
 	assert.NoError(t, err)
 }
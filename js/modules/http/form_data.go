@@ -1,18 +1,106 @@
 package http
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"slices"
+	"strings"
 
 	"github.com/grafana/sobek"
-	"github.com/shiroyk/ski"
 	"github.com/shiroyk/ski/js"
 )
 
-// fileData wraps the file data and filename
+// fileData wraps the file data, filename and the optional content type.
+// The reader and the path file are streamed to the multipart body.
 type fileData struct {
-	data     []byte
-	filename string
+	data        []byte
+	reader      io.Reader
+	path        string
+	filename    string
+	contentType string
+}
+
+// streaming reports whether the file content is streamed
+func (f fileData) streaming() bool { return f.reader != nil || f.path != "" }
+
+// writeTo writes the file content to the part
+func (f fileData) writeTo(w io.Writer) error {
+	switch {
+	case f.path != "":
+		file, err := os.Open(f.path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(w, file)
+		return err
+	case f.reader != nil:
+		_, err := io.Copy(w, f.reader)
+		return err
+	default:
+		_, err := w.Write(f.data)
+		return err
+	}
+}
+
+// toFileData converts the file descriptor { data, path, filename, type } to the fileData,
+// the data can be the io.Reader, the default filename is the base of the path or "blob".
+// The path is resolved in the root, see FormData.Root.
+func toFileData(desc map[string]any, root string) (fileData, error) {
+	f := fileData{filename: "blob"}
+	if path, ok := desc["path"].(string); ok && path != "" {
+		resolved, err := resolvePath(root, path)
+		if err != nil {
+			return fileData{}, err
+		}
+		f.path = resolved
+		f.filename = filepath.Base(path)
+	} else if reader, ok := desc["data"].(io.Reader); ok {
+		f.reader = reader
+	} else {
+		data, err := js.ToBytes(desc["data"])
+		if err != nil {
+			return fileData{}, fmt.Errorf("file data %s", err)
+		}
+		f.data = data
+	}
+	if filename, ok := desc["filename"].(string); ok && filename != "" {
+		f.filename = filename
+	}
+	if contentType, ok := desc["type"].(string); ok {
+		f.contentType = contentType
+	}
+	return f, nil
+}
+
+// resolvePath returns the path resolved in the root with the symlinks evaluated,
+// the relative path is relative to the root. The path outside the root is rejected.
+func resolvePath(root, path string) (string, error) {
+	if root == "" {
+		return "", errors.New("file path is not allowed, the FormData root is not set")
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return "", fmt.Errorf("file path root %s", err)
+	}
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return "", fmt.Errorf("file path root %s", err)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("file path %s", err)
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("file path %s is outside the root", path)
+	}
+	return resolved, nil
 }
 
 // formData provides a way to construct a set of key/value pairs representing form fields and their values.
@@ -21,18 +109,37 @@ type fileData struct {
 type formData struct {
 	keys []string
 	data map[string][]any
+	// subtype the multipart subtype, default "form-data"
+	subtype string
+	// boundary the multipart boundary, default random boundary
+	boundary string
+	// root the directory the file path is resolved in
+	root string
 }
 
 // FormData Constructor
-type FormData struct{}
+type FormData struct {
+	// Root the directory the file descriptor path is resolved in, the path outside is rejected.
+	// If empty, the path is not allowed, the scripts cannot read the local files.
+	Root string
+}
 
 // Instantiate returns module instance
-func (*FormData) Instantiate(rt *sobek.Runtime) (sobek.Value, error) {
+func (fd *FormData) Instantiate(rt *sobek.Runtime) (sobek.Value, error) {
 	return rt.ToValue(func(call sobek.ConstructorCall) *sobek.Object {
 		params := call.Argument(0)
 
-		var ret formData
-		if sobek.IsUndefined(params) {
+		ret := formData{root: fd.Root}
+		if options := call.Argument(1); !sobek.IsUndefined(options) && !sobek.IsNull(options) {
+			opt := options.ToObject(rt)
+			if v := opt.Get("type"); v != nil {
+				ret.subtype = v.String()
+			}
+			if v := opt.Get("boundary"); v != nil {
+				ret.boundary = v.String()
+			}
+		}
+		if sobek.IsUndefined(params) || sobek.IsNull(params) {
 			ret.data = make(map[string][]any)
 			return ret.object(rt)
 		}
@@ -57,6 +164,12 @@ func (*FormData) Instantiate(rt *sobek.Runtime) (sobek.Value, error) {
 					data:     ve.Bytes(),
 					filename: "blob",
 				}}
+			case map[string]any:
+				file, err := toFileData(ve, ret.root)
+				if err != nil {
+					js.Throw(rt, err)
+				}
+				ret.data[key] = []any{file}
 			case []any:
 				ret.data[key] = ve
 			case nil:
@@ -95,7 +208,8 @@ func (f *formData) object(rt *sobek.Runtime) *sobek.Object {
 
 // Append method of the formData interface appends a new value onto an existing key inside a formData object,
 // or adds the key if it does not already exist.
-func (f *formData) Append(name string, value any, filename string) sobek.Value {
+// The value can be the file descriptor { data, path, filename, type } to specify the file content type.
+func (f *formData) Append(name string, value any, filename string) (sobek.Value, error) {
 	if filename == "" {
 		// Default filename "blob".
 		filename = "blob"
@@ -118,13 +232,19 @@ func (f *formData) Append(name string, value any, filename string) sobek.Value {
 			data:     v.Bytes(),
 			filename: filename,
 		})
+	case map[string]any:
+		file, err := toFileData(v, f.root)
+		if err != nil {
+			return nil, err
+		}
+		ele = append(ele, file)
 	default:
 		ele = append(ele, fmt.Sprintf("%v", v))
 	}
 
 	f.data[name] = ele
 
-	return sobek.Undefined()
+	return sobek.Undefined(), nil
 }
 
 // Delete method of the formData interface deletes a key and its value(s) from a formData object.
@@ -146,7 +266,7 @@ func (f *formData) Entries() any {
 // with a given key from within a formData object.
 // If you expect multiple values and want all of them, use the getAll() method instead.
 func (f *formData) Get(name string) any {
-	if v, ok := f.data[name]; ok {
+	if v := f.data[name]; len(v) > 0 {
 		return v[0]
 	}
 	return nil
@@ -174,16 +294,34 @@ func (f *formData) Keys() any { return f.keys }
 
 // Set method of the formData interface sets a new value for an existing key inside a formData object,
 // or adds the key/value if it does not already exist.
-func (f *formData) Set(name string, value any, filename string) {
+// The value can be the file descriptor { data, path, filename, type } to specify the file content type.
+func (f *formData) Set(name string, value any, filename string) error {
 	if filename == "" {
 		filename = "blob"
 	}
 
+	var file fileData
+	if desc, ok := value.(map[string]any); ok {
+		var err error
+		if file, err = toFileData(desc, f.root); err != nil {
+			return err
+		}
+	}
+
 	if _, ok := f.data[name]; !ok {
 		f.keys = append(f.keys, name)
 	}
 
 	switch v := value.(type) {
+	case map[string]any:
+		f.data[name] = []any{file}
+	case []byte:
+		f.data[name] = []any{
+			fileData{
+				data:     v,
+				filename: filename,
+			},
+		}
 	case sobek.ArrayBuffer:
 		f.data[name] = []any{
 			fileData{
@@ -194,7 +332,15 @@ func (f *formData) Set(name string, value any, filename string) {
 	default:
 		f.data[name] = []any{fmt.Sprintf("%v", v)}
 	}
+	return nil
 }
 
 // Values method returns an iterator which iterates through all values contained in the formData.
-func (f *formData) Values() any { return ski.MapValues(f.data) }
+// The values are in the same order as the keys.
+func (f *formData) Values() any {
+	values := make([][]any, 0, len(f.keys))
+	for _, key := range f.keys {
+		values = append(values, f.data[key])
+	}
+	return values
+}
@@ -39,6 +39,16 @@ func TestFormData(t *testing.T) {
 		for (const [key, value] of form) {
 			str += key + ",";
 		}
-		assert.equal(str, 'file,name,')`)
+		assert.equal(str, 'file,name,')
+		form.append('avatar', { data: new Uint8Array([1]), filename: 'a.png', type: 'image/png' });
+		assert.true(form.has('avatar'));
+		form.set('avatar', { data: 'svg', filename: 'a.svg', type: 'image/svg+xml' });
+		assert.equal(form.getAll('avatar').length, 1);
+		try {
+			form.set('avatar', { data: 1 });
+			assert.true(false);
+		} catch (e) {
+			assert.true(e.toString().includes('file data expected'), e.toString());
+		}`)
 	assert.NoError(t, err)
 }
@@ -0,0 +1,106 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/grafana/sobek"
+	"github.com/shiroyk/ski/js"
+)
+
+// newHeaders returns the read-only Headers object of the response headers,
+// the headers can be accessed by the canonical name property for compatibility,
+// eg: headers["Content-Type"], or by the case-insensitive methods.
+// https://developer.mozilla.org/en-US/docs/Web/API/Headers
+func newHeaders(rt *sobek.Runtime, header http.Header) *sobek.Object {
+	object := rt.NewObject()
+	for k, v := range joinHeader(header) {
+		_ = object.Set(k, v)
+	}
+
+	get := func(name string) sobek.Value {
+		values := header.Values(name)
+		if len(values) == 0 {
+			return sobek.Null()
+		}
+		return rt.ToValue(strings.Join(values, ", "))
+	}
+
+	// the sorted lowercase header entries, the Set-Cookie is excluded from the combined values,
+	// each of its values is a separate entry as the Fetch spec
+	names := make([]string, 0, len(header))
+	for k := range header {
+		names = append(names, strings.ToLower(k))
+	}
+	slices.Sort(names)
+	type entry struct {
+		name  string
+		value sobek.Value
+	}
+	entries := make([]entry, 0, len(names))
+	for _, name := range names {
+		if name != "set-cookie" {
+			entries = append(entries, entry{name, get(name)})
+			continue
+		}
+		for _, v := range header.Values(name) {
+			entries = append(entries, entry{name, rt.ToValue(v)})
+		}
+	}
+	method := func(name string, fn any) {
+		_ = object.DefineDataProperty(name, rt.ToValue(fn), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_FALSE)
+	}
+
+	method("get", func(name string) sobek.Value { return get(name) })
+	method("has", func(name string) bool { return len(header.Values(name)) > 0 })
+	method("getSetCookie", func() *sobek.Object {
+		values := header.Values("Set-Cookie")
+		cookies := make([]any, len(values))
+		for i, v := range values {
+			cookies[i] = v
+		}
+		return rt.NewArray(cookies...)
+	})
+	method("keys", func() *sobek.Object {
+		keys := make([]any, len(entries))
+		for i, e := range entries {
+			keys[i] = e.name
+		}
+		return rt.NewArray(keys...)
+	})
+	method("entries", func() *sobek.Object {
+		values := make([]any, len(entries))
+		for i, e := range entries {
+			values[i] = rt.NewArray(e.name, e.value)
+		}
+		return rt.NewArray(values...)
+	})
+	method("forEach", func(call sobek.FunctionCall) sobek.Value {
+		callback, ok := sobek.AssertFunction(call.Argument(0))
+		if !ok {
+			js.Throw(rt, errors.New("forEach callback must be a function"))
+		}
+		for _, e := range entries {
+			if _, err := callback(call.Argument(1), e.value, rt.ToValue(e.name), object); err != nil {
+				js.Throw(rt, err)
+			}
+		}
+		return sobek.Undefined()
+	})
+	_ = object.DefineDataPropertySymbol(sobek.SymIterator, rt.ToValue(func(sobek.FunctionCall) sobek.Value {
+		var i int
+		it := rt.NewObject()
+		_ = it.Set("next", func(sobek.FunctionCall) sobek.Value {
+			if i < len(entries) {
+				e := entries[i]
+				i++
+				return rt.ToValue(iter{Value: rt.ToValue([2]any{e.name, e.value})})
+			}
+			return rt.ToValue(iter{Done: true})
+		})
+		return it
+	}), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_FALSE)
+	return object
+}
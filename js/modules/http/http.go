@@ -4,19 +4,26 @@ package http
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"mime/multipart"
+	"net"
 	"net/http"
+	"net/textproto"
 	urlpkg "net/url"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/grafana/sobek"
 	"github.com/shiroyk/ski"
 	"github.com/shiroyk/ski/js"
 	"github.com/spf13/cast"
+	"golang.org/x/net/html/charset"
 )
 
 func init() {
@@ -44,12 +51,11 @@ func (fetch *Fetch) Instantiate(rt *sobek.Runtime) (sobek.Value, error) {
 	}
 	return rt.ToValue(func(call sobek.FunctionCall, vm *sobek.Runtime) sobek.Value {
 		req, signal := buildRequest(http.MethodGet, call, vm)
+		signal.releaseOnDone(vm)
 		return vm.ToValue(js.NewPromise(vm,
 			func() (*http.Response, error) {
-				if signal != nil {
-					defer signal.abort() // release resources
-				}
-				return fetch.Do(req)
+				res, err := checkStatus(fetch.Do(req))
+				return res, signal.wrap(err)
 			},
 			func(res *http.Response, err error) (any, error) {
 				if err != nil {
@@ -70,13 +76,16 @@ func (h *Http) Instantiate(rt *sobek.Runtime) (sobek.Value, error) {
 		return nil, errors.New("Fetch can not nil")
 	}
 	return rt.ToValue(map[string]func(call sobek.FunctionCall, vm *sobek.Runtime) sobek.Value{
-		"get":     h.Get,
-		"post":    h.Post,
-		"put":     h.Put,
-		"delete":  h.Delete,
-		"patch":   h.Patch,
-		"request": h.Request,
-		"head":    h.Head,
+		"get":        h.Get,
+		"post":       h.Post,
+		"put":        h.Put,
+		"delete":     h.Delete,
+		"patch":      h.Patch,
+		"request":    h.Request,
+		"head":       h.Head,
+		"options":    h.Options,
+		"all":        h.All,
+		"allSettled": h.AllSettled,
 	}), nil
 }
 
@@ -92,6 +101,10 @@ func (h *Http) Get(call sobek.FunctionCall, vm *sobek.Runtime) sobek.Value {
 // http.post(url, { body: new URLSearchParams({'key': 'foo', 'value': 'bar'}) })
 // Send POST with json:
 // http.post(url, { body: {'key': 'foo'} })
+// Send POST with xml:
+// http.post(url, { body: {'user': {'@id': '1', 'name': 'foo'}}, type: 'xml' })
+// Send POST with newline-delimited json:
+// http.post(url, { body: [{'key': 'foo'}, {'key': 'bar'}], type: 'ndjson' })
 func (h *Http) Post(call sobek.FunctionCall, vm *sobek.Runtime) sobek.Value {
 	return h.do(call, vm, http.MethodPost)
 }
@@ -112,8 +125,33 @@ func (h *Http) Patch(call sobek.FunctionCall, vm *sobek.Runtime) sobek.Value {
 }
 
 // Request Make a HTTP request.
+// The low-level form accepts the options object with the url property,
+// the method is sent as-is without the upper casing:
+// http.request({ url, method: 'purge', host: 'example.com', headers: [['X-A', '1'], ['X-A', '2']] })
 func (h *Http) Request(call sobek.FunctionCall, vm *sobek.Runtime) sobek.Value {
-	return h.do(call, vm, http.MethodGet)
+	obj, ok := call.Argument(0).(*sobek.Object)
+	if !ok || obj.ClassName() != "Object" || obj.Get("url") == nil {
+		return h.do(call, vm, http.MethodGet)
+	}
+
+	req, signal := buildRequest(http.MethodGet, sobek.FunctionCall{
+		Arguments: []sobek.Value{obj.Get("url"), obj}}, vm)
+	signal.releaseOnDone(vm)
+	if v := obj.Get("method"); v != nil {
+		req.Method = v.String()
+	}
+
+	res, err := checkStatus(h.Do(req))
+	if err != nil {
+		js.Throw(vm, signal.wrap(err))
+	}
+
+	return NewResponse(vm, res)
+}
+
+// Options Make a HTTP OPTIONS request.
+func (h *Http) Options(call sobek.FunctionCall, vm *sobek.Runtime) sobek.Value {
+	return h.do(call, vm, http.MethodOptions)
 }
 
 // Head Make a HTTP HEAD request.
@@ -121,15 +159,106 @@ func (h *Http) Head(call sobek.FunctionCall, vm *sobek.Runtime) sobek.Value {
 	return h.do(call, vm, http.MethodHead)
 }
 
+// defaultConcurrency the default maximum number of concurrent requests of the All and AllSettled
+const defaultConcurrency = 6
+
+// All Make the HTTP requests concurrently and returns the responses in order,
+// throws the aggregated error if any request fails.
+// The request descriptor is the URL string, the [url, options] array,
+// or the options object with the url property.
+// The optional second argument is the maximum number of concurrent requests, default 6.
+// http.all([url, [url, { method: 'POST', body: 'foo' }], { url, method: 'PUT' }], 2)
+func (h *Http) All(call sobek.FunctionCall, vm *sobek.Runtime) sobek.Value {
+	results := h.all(call, vm)
+	ret := make([]any, len(results))
+	var errs []error
+	for i, r := range results {
+		if r.err != nil {
+			errs = append(errs, fmt.Errorf("request %d: %w", i, r.err))
+			continue
+		}
+		ret[i] = NewResponse(vm, r.res)
+	}
+	if len(errs) > 0 {
+		js.Throw(vm, errors.Join(errs...))
+	}
+	return vm.NewArray(ret...)
+}
+
+// AllSettled Make the HTTP requests concurrently like All, returns the results in order
+// without throwing, the result is { status: 'fulfilled', value: response }
+// or { status: 'rejected', reason: error }.
+func (h *Http) AllSettled(call sobek.FunctionCall, vm *sobek.Runtime) sobek.Value {
+	results := h.all(call, vm)
+	ret := make([]any, len(results))
+	for i, r := range results {
+		if r.err != nil {
+			// the reason is the same error value as the thrown one, eg: e.status of the statusError
+			ret[i] = map[string]any{"status": "rejected", "reason": vm.ToValue(r.err)}
+			continue
+		}
+		ret[i] = map[string]any{"status": "fulfilled", "value": NewResponse(vm, r.res)}
+	}
+	return vm.NewArray(ret...)
+}
+
+type result struct {
+	res *http.Response
+	err error
+}
+
+func (h *Http) all(call sobek.FunctionCall, vm *sobek.Runtime) []result {
+	var descriptors []sobek.Value
+	if err := vm.ExportTo(call.Argument(0), &descriptors); err != nil {
+		js.Throw(vm, fmt.Errorf("requests must be array, %s", err))
+	}
+	concurrency := defaultConcurrency
+	if v := call.Argument(1); !sobek.IsUndefined(v) && !sobek.IsNull(v) {
+		if concurrency = int(v.ToInteger()); concurrency <= 0 {
+			js.Throw(vm, errors.New("concurrency must be greater than 0"))
+		}
+	}
+
+	// build the requests on the runtime goroutine, the runtime is not goroutine-safe
+	requests := make([]*http.Request, len(descriptors))
+	signals := make([]*abortSignal, len(descriptors))
+	for i, desc := range descriptors {
+		args := []sobek.Value{desc}
+		if obj, ok := desc.(*sobek.Object); ok {
+			if obj.ClassName() == "Array" {
+				args = []sobek.Value{obj.Get("0"), obj.Get("1")}
+			} else {
+				args = []sobek.Value{obj.Get("url"), obj}
+			}
+		}
+		req, signal := buildRequest(http.MethodGet, sobek.FunctionCall{Arguments: args}, vm)
+		signal.releaseOnDone(vm)
+		requests[i], signals[i] = req, signal
+	}
+
+	results := make([]result, len(requests))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, req := range requests {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, req *http.Request) {
+			defer func() { <-sem; wg.Done() }()
+			res, err := checkStatus(h.Do(req))
+			results[i].res, results[i].err = res, signals[i].wrap(err)
+		}(i, req)
+	}
+	wg.Wait()
+	return results
+}
+
 func (h *Http) do(call sobek.FunctionCall, vm *sobek.Runtime, method string) sobek.Value {
 	req, signal := buildRequest(method, call, vm)
-	if signal != nil {
-		defer signal.abort() // release resources
-	}
+	signal.releaseOnDone(vm)
 
-	res, err := h.Do(req)
+	res, err := checkStatus(h.Do(req))
 	if err != nil {
-		js.Throw(vm, err)
+		js.Throw(vm, signal.wrap(err))
 	}
 
 	return NewResponse(vm, res)
@@ -147,6 +276,8 @@ func buildRequest(
 		opt     *sobek.Object
 		body    io.Reader
 		headers = make(map[string]string)
+		values  map[string][]string
+		te      []string
 		err     error
 	)
 
@@ -160,17 +291,65 @@ func buildRequest(
 		method = strings.ToUpper(v.String())
 	}
 	if v := opt.Get("headers"); v != nil {
-		if headers, err = cast.ToStringMapStringE(v.Export()); err != nil {
+		if pairs, ok := v.Export().([]any); ok {
+			headers, values, err = headerPairs(pairs)
+		} else {
+			headers, err = cast.ToStringMapStringE(v.Export())
+		}
+		if err != nil {
 			js.Throw(vm, fmt.Errorf("options headers is invalid, %s", err))
 		}
 	}
 	if method != http.MethodGet && method != http.MethodHead {
 		if v := opt.Get("body"); v != nil {
-			if body, err = processBody(v.Export(), headers); err != nil {
+			var typ string
+			if t := opt.Get("type"); t != nil && !sobek.IsUndefined(t) && !sobek.IsNull(t) {
+				typ = t.String()
+			}
+			switch typ {
+			case "":
+				body, err = processBody(v.Export(), headers)
+			case "xml":
+				body, err = xmlBody(v.Export(), headers)
+			case "ndjson":
+				body, err = ndjsonBody(v.Export(), headers)
+			default:
+				err = fmt.Errorf("options type %s is not supported, must be xml or ndjson", typ)
+			}
+			if err != nil {
 				js.Throw(vm, err)
 			}
 		}
 	}
+	if v := opt.Get("auth"); v != nil {
+		auth, err := authorization(v.Export())
+		if err != nil {
+			js.Throw(vm, err)
+		}
+		for k := range headers {
+			if strings.EqualFold(k, "Authorization") {
+				delete(headers, k)
+			}
+		}
+		headers["Authorization"] = auth
+	}
+	if v := opt.Get("acceptEncoding"); v != nil {
+		var accept string
+		if s, ok := v.Export().(string); ok {
+			accept = s
+		} else if encodings, err := cast.ToStringSliceE(v.Export()); err == nil {
+			accept = strings.Join(encodings, ", ")
+		}
+		if strings.TrimSpace(accept) == "" {
+			js.Throw(vm, errors.New("options acceptEncoding is invalid, expected string or array"))
+		}
+		for k := range headers {
+			if strings.EqualFold(k, "Accept-Encoding") {
+				delete(headers, k)
+			}
+		}
+		headers["Accept-Encoding"] = accept
+	}
 	if v := opt.Get("cache"); v != nil {
 		str := v.String()
 		headers["Cache-Control"] = str
@@ -185,12 +364,66 @@ func buildRequest(
 	} else {
 		ctx = js.Context(vm)
 	}
+	if v := opt.Get("timeout"); v != nil {
+		timeout := time.Duration(v.ToInteger()) * time.Millisecond
+		if timeout <= 0 {
+			js.Throw(vm, fmt.Errorf("options timeout %s must be positive", v))
+		}
+		// the timeout signal derives from the signal, whichever fires first wins
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout,
+			fmt.Errorf("%w after %s", errRequestTimeout, timeout))
+		signal = &abortSignal{ctx: ctx, cancel: func(error) { cancel() }}
+	}
 	if v := opt.Get("proxy"); v != nil {
 		proxy, err := urlpkg.Parse(v.String())
 		if err != nil {
 			js.Throw(vm, fmt.Errorf("options proxy is invalid URL, %s", err))
 		}
+		if v := opt.Get("proxyAuth"); v != nil {
+			if proxy.User, err = proxyUserinfo(v.Export()); err != nil {
+				js.Throw(vm, err)
+			}
+		}
 		ctx = ski.WithProxyURL(ctx, proxy)
+	} else if opt.Get("proxyAuth") != nil {
+		js.Throw(vm, errors.New("options proxyAuth requires the proxy option"))
+	}
+	if v := opt.Get("localAddr"); v != nil {
+		ip := net.ParseIP(v.String())
+		if ip == nil {
+			js.Throw(vm, fmt.Errorf("options localAddr is invalid IP %s", v))
+		}
+		ctx = ski.WithLocalAddr(ctx, ip)
+	}
+	if v := opt.Get("redirect"); v != nil {
+		switch mode := ski.RedirectMode(v.String()); mode {
+		case ski.RedirectFollow, ski.RedirectManual, ski.RedirectError:
+			ctx = ski.WithRedirectMode(ctx, mode)
+		default:
+			js.Throw(vm, fmt.Errorf("options redirect %s is invalid, must be follow, manual or error", mode))
+		}
+	}
+	if v := opt.Get("throwOnError"); v != nil {
+		ctx = context.WithValue(ctx, &throwOnErrorKey, v.ToBoolean())
+	}
+	if v := opt.Get("decodeCharset"); v != nil {
+		ctx = context.WithValue(ctx, &decodeCharsetKey, v.ToBoolean())
+	}
+	if v := opt.Get("encoding"); v != nil {
+		label := v.String()
+		if _, name := charset.Lookup(label); name == "" {
+			js.Throw(vm, fmt.Errorf("options encoding %s is unknown", label))
+		}
+		ctx = context.WithValue(ctx, &encodingKey, label)
+	}
+	if v := opt.Get("transferEncoding"); v != nil {
+		if te, err = cast.ToStringSliceE(v.Export()); err != nil {
+			js.Throw(vm, fmt.Errorf("options transferEncoding is invalid, %s", err))
+		}
+		if err = checkTransferEncoding(te, body, headers); err != nil {
+			js.Throw(vm, err)
+		}
 	}
 
 NEW:
@@ -200,45 +433,331 @@ NEW:
 	}
 
 	for k, v := range headers {
-		req.Header.Set(k, v)
+		// the header pairs are kept unless overridden by other options
+		if vs, ok := values[k]; ok && vs[len(vs)-1] == v {
+			req.Header[k] = vs
+		} else {
+			req.Header.Set(k, v)
+		}
+	}
+	if len(te) > 0 {
+		req.TransferEncoding = te
+	}
+	if host := req.Header.Get("Host"); host != "" {
+		req.Host = host
+	}
+	if opt == nil {
+		return
+	}
+	if v := opt.Get("strictURL"); v != nil && v.ToBoolean() {
+		// the same validation as the FetchOptions.StrictURL, before the request is dispatched
+		if err = ski.ValidateURL(req.URL); err != nil {
+			js.Throw(vm, err)
+		}
+	}
+	if v := opt.Get("host"); v != nil {
+		req.Host = v.String()
+	}
+	if v := opt.Get("trailers"); v != nil {
+		trailers, err := cast.ToStringMapStringE(v.Export())
+		if err != nil {
+			js.Throw(vm, fmt.Errorf("options trailers is invalid, %s", err))
+		}
+		if body == nil {
+			js.Throw(vm, errors.New("options trailers requires the body"))
+		}
+		req.Trailer = make(http.Header, len(trailers))
+		for k, v := range trailers {
+			req.Trailer.Set(k, v)
+		}
+		// the trailers are only sent with the chunked body
+		req.ContentLength = -1
 	}
 
 	return
 }
 
-// processBody process the send request body and set the content-type
-func processBody(body any, headers map[string]string) (io.Reader, error) {
-	switch data := body.(type) {
-	case *formData:
-		buf := new(bytes.Buffer)
-		mpw := multipart.NewWriter(buf)
+// headerPairs converts the [name, value] pairs to the headers, the values of the same name
+// are kept in order. The headers has the last value of each canonical name.
+func headerPairs(pairs []any) (map[string]string, map[string][]string, error) {
+	headers := make(map[string]string, len(pairs))
+	values := make(map[string][]string, len(pairs))
+	for i, pair := range pairs {
+		kv, err := cast.ToStringSliceE(pair)
+		if err != nil || len(kv) != 2 {
+			return nil, nil, fmt.Errorf("header %d expected [name, value] pair", i)
+		}
+		k := http.CanonicalHeaderKey(kv[0])
+		headers[k] = kv[1]
+		values[k] = append(values[k], kv[1])
+	}
+	return headers, values, nil
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// createFormFile creates the form-data file part, the content type
+// is "application/octet-stream" if not specified.
+func createFormFile(mpw *multipart.Writer, field string, f fileData) (io.Writer, error) {
+	if f.contentType == "" {
+		return mpw.CreateFormFile(field, f.filename)
+	}
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(field), quoteEscaper.Replace(f.filename)))
+	h.Set("Content-Type", f.contentType)
+	return mpw.CreatePart(h)
+}
+
+// setContentType sets the Content-Type if the header is not supplied by the user
+func setContentType(headers map[string]string, contentType string) {
+	for k := range headers {
+		if strings.EqualFold(k, "Content-Type") {
+			return
+		}
+	}
+	headers["Content-Type"] = contentType
+}
+
+// checkTransferEncoding validates the request transfer encodings,
+// only "chunked" and "identity" are supported by the transport.
+// The "identity" disables the automatic chunked encoding, it cannot
+// be combined with others.
+func checkTransferEncoding(te []string, body io.Reader, headers map[string]string) error {
+	if len(te) == 0 {
+		return errors.New("options transferEncoding is empty")
+	}
+	for i, v := range te {
+		te[i] = strings.ToLower(strings.TrimSpace(v))
+		switch te[i] {
+		case "chunked":
+			if body == nil || body == http.NoBody {
+				return errors.New("options transferEncoding chunked requires a request body")
+			}
+			for k := range headers {
+				if strings.EqualFold(k, "Content-Length") {
+					return errors.New("options transferEncoding chunked conflicts with Content-Length header")
+				}
+			}
+		case "identity":
+		default:
+			return fmt.Errorf("options transferEncoding %s is not supported", v)
+		}
+	}
+	if len(te) > 1 {
+		return fmt.Errorf("options transferEncoding %s cannot be combined", strings.Join(te, ", "))
+	}
+	return nil
+}
+
+// proxyUserinfo returns the proxy credentials from the "username:password"
+// or { username, password }, which is sent as the Proxy-Authorization basic header.
+func proxyUserinfo(auth any) (*urlpkg.Userinfo, error) {
+	switch v := auth.(type) {
+	case string:
+		if username, password, ok := strings.Cut(v, ":"); ok {
+			return urlpkg.UserPassword(username, password), nil
+		}
+		return urlpkg.User(v), nil
+	case map[string]any:
+		username, _ := v["username"].(string)
+		if username == "" {
+			return nil, errors.New("options proxyAuth username is required")
+		}
+		if password, ok := v["password"].(string); ok {
+			return urlpkg.UserPassword(username, password), nil
+		}
+		return urlpkg.User(username), nil
+	default:
+		return nil, fmt.Errorf("options proxyAuth expected string or object, but got %T", auth)
+	}
+}
+
+var throwOnErrorKey byte
+
+// maxErrorBody the maximum length of the response body snippet in the statusError
+const maxErrorBody = 512
+
+// statusError the 4xx or 5xx response error of the throwOnError option,
+// the fields are accessible in JS, eg: e.status, e.body.
+type statusError struct {
+	Status     int
+	StatusText string
+	URL        string
+	// Body the snippet of the response body
+	Body string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("HTTPError: %s %s: %s", e.StatusText, e.URL, e.Body)
+}
+
+// checkStatus returns the statusError and closes the body if the response status
+// is 4xx or 5xx and the request throwOnError option is enabled.
+func checkStatus(res *http.Response, err error) (*http.Response, error) {
+	if err != nil || res.StatusCode < http.StatusBadRequest {
+		return res, err
+	}
+	if enabled, _ := res.Request.Context().Value(&throwOnErrorKey).(bool); !enabled {
+		return res, nil
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBody))
+	return nil, &statusError{
+		Status:     res.StatusCode,
+		StatusText: res.Status,
+		URL:        res.Request.URL.String(),
+		Body:       strings.ToValidUTF8(string(body), ""),
+	}
+}
+
+// authorization returns the Authorization header value from the
+// { type: "basic", username, password } or { type: "bearer", token },
+// the basic credentials are the base64 encoded UTF-8 "username:password".
+func authorization(auth any) (string, error) {
+	v, ok := auth.(map[string]any)
+	if !ok {
+		return "", fmt.Errorf("options auth expected object, but got %T", auth)
+	}
+	typ, _ := v["type"].(string)
+	switch strings.ToLower(typ) {
+	case "basic":
+		username, _ := v["username"].(string)
+		if username == "" {
+			return "", errors.New("options auth username is required")
+		}
+		if strings.Contains(username, ":") {
+			return "", errors.New("options auth username must not contain colon")
+		}
+		password, _ := v["password"].(string)
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password)), nil
+	case "bearer":
+		token, _ := v["token"].(string)
+		if token == "" {
+			return "", errors.New("options auth token is required")
+		}
+		return "Bearer " + token, nil
+	default:
+		return "", fmt.Errorf("options auth type %s is not supported, must be basic or bearer", typ)
+	}
+}
+
+// ndjsonBody serializes each element of the array body as a JSON line and set the content-type
+func ndjsonBody(body any, headers map[string]string) (io.Reader, error) {
+	lines, ok := body.([]any)
+	if !ok {
+		return nil, fmt.Errorf("options type ndjson expected array body, got %T", body)
+	}
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	for i, line := range lines {
+		if err := enc.Encode(line); err != nil {
+			return nil, fmt.Errorf("ndjson line %d: %w", i, err)
+		}
+	}
+	setContentType(headers, "application/x-ndjson")
+	return buf, nil
+}
+
+// multipartBody writes the form data to the multipart body and set the content-type.
+// If any file is the reader or the path, the body is streamed by the multipartStream
+// instead of buffering the whole files, the write error is returned by the body Read.
+func multipartBody(data *formData, headers map[string]string) (io.Reader, error) {
+	var stream bool
+	for _, values := range data.data {
+		for _, value := range values {
+			if f, ok := value.(fileData); ok && f.streaming() {
+				stream = true
+			}
+		}
+	}
+	buf := new(bytes.Buffer)
+	mpw := multipart.NewWriter(buf)
+	if data.boundary != "" {
+		if err := mpw.SetBoundary(data.boundary); err != nil {
+			return nil, err
+		}
+	}
+	if data.subtype == "" || data.subtype == "form-data" {
+		headers["Content-Type"] = mpw.FormDataContentType()
+	} else {
+		headers["Content-Type"] = mime.FormatMediaType("multipart/"+data.subtype,
+			map[string]string{"boundary": mpw.Boundary()})
+	}
+
+	write := func(mpw *multipart.Writer) error {
 		for _, key := range data.keys {
 			for _, value := range data.data[key] {
 				if f, ok := value.(fileData); ok {
 					// Creates a new form-data header with the provided field name and file name.
-					fw, err := mpw.CreateFormFile(key, f.filename)
+					fw, err := createFormFile(mpw, key, f)
 					if err != nil {
-						return nil, err
+						return err
 					}
-					// Write bytes to the part
-					if _, err = fw.Write(f.data); err != nil {
-						return nil, err
+					if err = f.writeTo(fw); err != nil {
+						return fmt.Errorf("form file %s: %w", key, err)
 					}
 				} else {
 					// Write string value
-					if err := mpw.WriteField(key, fmt.Sprintf("%v", key)); err != nil {
-						return nil, err
+					if err := mpw.WriteField(key, fmt.Sprintf("%v", value)); err != nil {
+						return err
 					}
 				}
 			}
 		}
-		headers["Content-Type"] = mpw.FormDataContentType()
-		if err := mpw.Close(); err != nil {
+		return mpw.Close()
+	}
+
+	if !stream {
+		if err := write(mpw); err != nil {
 			return nil, err
 		}
 		return buf, nil
+	}
+	boundary := mpw.Boundary()
+	return &multipartStream{write: func(w io.Writer) error {
+		mpw := multipart.NewWriter(w)
+		_ = mpw.SetBoundary(boundary)
+		return write(mpw)
+	}}, nil
+}
+
+// multipartStream streams the multipart body through a pipe, the writer starts on the first Read.
+// The body which is never read, eg: the request options are invalid, leaks neither
+// the writer goroutine nor the opened files, Close stops the started writer.
+type multipartStream struct {
+	write func(w io.Writer) error
+	once  sync.Once
+	pr    *io.PipeReader
+}
+
+func (s *multipartStream) Read(p []byte) (int, error) {
+	s.once.Do(func() {
+		var pw *io.PipeWriter
+		s.pr, pw = io.Pipe()
+		go func() { _ = pw.CloseWithError(s.write(pw)) }()
+	})
+	if s.pr == nil {
+		return 0, io.ErrClosedPipe
+	}
+	return s.pr.Read(p)
+}
+
+func (s *multipartStream) Close() error {
+	s.once.Do(func() {}) // never start the writer after closed
+	if s.pr == nil {
+		return nil
+	}
+	return s.pr.Close()
+}
+
+// processBody process the send request body and set the content-type
+func processBody(body any, headers map[string]string) (io.Reader, error) {
+	switch data := body.(type) {
+	case *formData:
+		return multipartBody(data, headers)
 	case *urlSearchParams:
-		headers["Content-Type"] = "application/x-www-form-url"
+		setContentType(headers, "application/x-www-form-urlencoded")
 		return strings.NewReader(data.encode()), nil
 	case string:
 		return strings.NewReader(data), nil
@@ -247,7 +766,7 @@ func processBody(body any, headers map[string]string) (io.Reader, error) {
 	case []byte:
 		return bytes.NewReader(data), nil
 	case map[string]any:
-		headers["Content-Type"] = "application/json"
+		setContentType(headers, "application/json")
 		marshal, err := json.Marshal(data)
 		if err != nil {
 			return nil, err
@@ -1,12 +1,21 @@
 package http
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -22,8 +31,70 @@ func TestHttp(t *testing.T) {
 	testCase := []string{
 		`assert.equal(http.get(url).text(), "");`,
 		`assert.equal(http.post(url, { body: new FormData({'file': fa, 'name': 'foo'}) }).text(), "♂︎");`,
+		`const res = http.post(url + "/mixed", { body: new FormData({'file': fa}, {type: 'mixed', boundary: 'fixed-boundary'}) });
+		 assert.equal(res.headers["X-Content-Type"], "multipart/mixed; boundary=fixed-boundary");
+		 assert.true(res.text().startsWith("--fixed-boundary\r\n"));`,
 		`assert.equal(http.post(url, { body: new URLSearchParams({'key': 'holy', 'value': 'fa'}) }).text(), "key=holy&value=fa");`,
+		`const form = http.post(url + "/mixed", { body: new URLSearchParams({'key': 'holy'}) });
+		 assert.equal(form.headers["X-Content-Type"], "application/x-www-form-urlencoded");
+		 assert.equal(form.text(), "key=holy");`,
+		`const custom = http.post(url + "/mixed", {
+			body: new URLSearchParams({'key': 'holy'}),
+			headers: {'content-type': 'application/x-www-form-urlencoded; charset=utf-8'},
+		 });
+		 assert.equal(custom.headers["X-Content-Type"], "application/x-www-form-urlencoded; charset=utf-8");`,
+		`const json = http.post(url + "/mixed", { body: {'a': 1} });
+		 assert.equal(json.headers["X-Content-Type"], "application/json");`,
+		`const avatar = http.post(url + "/mixed", { body: new FormData({
+			'avatar': { data: new Uint8Array([137, 80]), filename: 'a.png', type: 'image/png' },
+			'file': fa,
+		 }) }).text();
+		 assert.true(avatar.includes('Content-Disposition: form-data; name="avatar"; filename="a.png"\r\nContent-Type: image/png\r\n'), avatar);
+		 assert.true(avatar.includes('Content-Disposition: form-data; name="file"; filename="blob"\r\nContent-Type: application/octet-stream\r\n'), avatar);`,
+		`const steps = new FormData(null, {boundary: 'step-boundary'});
+		 steps.append('name', 'foo');
+		 steps.append('name', 'bar');
+		 steps.append('file', fa, 'fa.txt');
+		 steps.set('id', 114);
+		 steps.append('tmp', 'tmp');
+		 steps.delete('tmp');
+		 assert.equal(steps.getAll('name').join(), 'foo,bar');
+		 const multipart = http.post(url + "/mixed", { body: steps }).text();
+		 assert.equal(multipart, [
+			'--step-boundary',
+			'Content-Disposition: form-data; name="name"', '', 'foo',
+			'--step-boundary',
+			'Content-Disposition: form-data; name="name"', '', 'bar',
+			'--step-boundary',
+			'Content-Disposition: form-data; name="file"; filename="fa.txt"',
+			'Content-Type: application/octet-stream', '', '♂︎',
+			'--step-boundary',
+			'Content-Disposition: form-data; name="id"', '', '114',
+			'--step-boundary--', '',
+		 ].join('\r\n'));`,
+		`assert.equal(http.get(url + "/redirect").status, 200);`,
+		`const manual = http.get(url + "/redirect", { redirect: "manual" });
+		 assert.equal(manual.status, 302);
+		 assert.equal(manual.headers.get("location"), "/");`,
+		`try {
+			http.get(url + "/redirect", { redirect: "error" });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("redirect is not allowed"), e.toString());
+		 }`,
+		`try {
+			http.get(url + "/redirect", { redirect: "never" });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("options redirect never is invalid"), e.toString());
+		 }`,
 		`assert.equal(http.head(url).headers["X-Total-Count"], "114514");`,
+		`const patched = http.patch(url, { body: {'op': 'merge'} });
+		 assert.equal(patched.headers["X-Method"], "PATCH");
+		 assert.equal(patched.json()['op'], "merge");`,
+		`const options = http.options(url);
+		 assert.equal(options.headers["X-Method"], "OPTIONS");
+		 assert.equal(options.status, 200);`,
 		`assert.equal(http.post(url).text(), "");`,
 		`assert.equal(new Uint8Array(http.post(url, { body: '1' }).arrayBuffer())[0], 49);`,
 		`assert.equal(http.post(url, { body: {'dark': 'o'} }).json()['dark'], "o");`,
@@ -55,7 +126,7 @@ func TestHttp(t *testing.T) {
 		 assert.true(controller.aborted);`,
 		`(async () => {
 			try {
-				await fetch(url, { signal: AbortSignal.timeout(500), body: "sleep1000" });
+				await fetch(url, { signal: AbortSignal.timeout(50), body: "sleep200000000" });
 			} catch (e) {
 				assert.true(e.toString().includes("context deadline exceeded"), e);
 			}
@@ -70,8 +141,119 @@ func TestHttp(t *testing.T) {
 	}
 }
 
+func TestHttpAll(t *testing.T) {
+	vm := createVM(t)
+	testCase := []string{
+		`const res = http.all([
+			[url, { method: 'POST', body: 'sleep50000000' }],
+			{ url: url, method: 'PUT', body: 'put', headers: {"Authorization": "1919810"} },
+			url,
+		 ], 2);
+		 assert.equal(res.length, 3);
+		 assert.equal(res[0].text(), "sleep50000000");
+		 assert.equal(res[1].text(), "put");
+		 assert.equal(res[2].text(), "");`,
+		`try {
+			http.all([url, "http://127.0.0.1:0", "http://127.0.0.1:0/2"]);
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("request 1:"), e.toString());
+			assert.true(e.toString().includes("request 2:"), e.toString());
+		 }`,
+		`const settled = http.allSettled([{ url: url, method: 'POST', body: 'ok' }, "http://127.0.0.1:0"]);
+		 assert.equal(settled[0].status, "fulfilled");
+		 assert.equal(settled[0].value.text(), "ok");
+		 assert.equal(settled[1].status, "rejected");
+		 assert.equal(typeof settled[1].reason, "object");
+		 assert.true(settled[1].reason.toString().length > 0);`,
+		`const timeouts = http.allSettled([[url, { method: 'POST', timeout: 10, body: 'sleep200000000' }]]);
+		 assert.equal(timeouts[0].status, "rejected");
+		 assert.true(timeouts[0].reason.toString().includes("TimeoutError"), timeouts[0].reason.toString());`,
+		`try {
+			http.all([url], 0);
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("concurrency must be greater than 0"), e.toString());
+		 }`,
+	}
+
+	for i, s := range testCase {
+		t.Run(fmt.Sprintf("Script%v", i), func(t *testing.T) {
+			_, err := vm.Runtime().RunString(s)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestTransferEncoding(t *testing.T) {
+	vm := createVM(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer ln.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		req, err := http.ReadRequest(bufio.NewReader(conn))
+		if err != nil {
+			received <- err.Error()
+			return
+		}
+		body, _ := io.ReadAll(req.Body)
+		received <- strings.Join(req.TransferEncoding, ",") + " " + string(body)
+		_, _ = fmt.Fprint(conn, "HTTP/1.1 200 OK\r\nContent-Length: 0\r\nConnection: close\r\n\r\n")
+	}()
+
+	_, err = vm.Runtime().RunString(fmt.Sprintf(`http.post("http://%s", { body: "raw", transferEncoding: "chunked" })`, ln.Addr()))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "chunked raw", <-received)
+	}
+
+	testCase := []string{
+		`try {
+			http.post(url, { body: "raw", transferEncoding: "gzip" });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("transferEncoding gzip is not supported"), e.toString());
+		 }`,
+		`try {
+			http.post(url, { body: "raw", transferEncoding: ["identity", "chunked"] });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("cannot be combined"), e.toString());
+		 }`,
+		`try {
+			http.get(url, { transferEncoding: "chunked" });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("requires a request body"), e.toString());
+		 }`,
+		`try {
+			http.post(url, { body: "raw", headers: {"content-length": "3"}, transferEncoding: "chunked" });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("conflicts with Content-Length"), e.toString());
+		 }`,
+		`assert.equal(http.post(url, { body: "raw", transferEncoding: "identity" }).text(), "raw");`,
+	}
+
+	for i, s := range testCase {
+		t.Run(fmt.Sprintf("Script%v", i), func(t *testing.T) {
+			_, err := vm.Runtime().RunString(s)
+			assert.NoError(t, err)
+		})
+	}
+}
+
 var initial = js.WithInitial(func(rt *sobek.Runtime) {
-	client := http.Client{Transport: &http.Transport{Proxy: ski.ProxyFromRequest}}
+	client := http.Client{Transport: &http.Transport{Proxy: ski.ProxyFromRequest}, CheckRedirect: ski.CheckRedirect}
 	instance, _ := (&Http{&client}).Instantiate(rt)
 	_ = rt.Set("http", instance)
 	f, _ := (&Fetch{&client}).Instantiate(rt)
@@ -91,8 +273,20 @@ func createVM(t *testing.T) modulestest.VM {
 			_, err := fmt.Fprint(w, "CUSTOM")
 			assert.NoError(t, err)
 		}
-		w.Header().Set("Content-Type", "text/plain; charset=iso-8859-9")
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 		w.Header().Set("X-Total-Count", "114514")
+		w.Header().Set("X-Method", r.Method)
+
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/", http.StatusFound)
+			return
+		}
+		if r.URL.Path == "/mixed" {
+			w.Header().Set("X-Content-Type", r.Header.Get("Content-Type"))
+			_, err := io.Copy(w, r.Body)
+			assert.NoError(t, err)
+			return
+		}
 
 		isMp := strings.Contains(r.Header.Get("Content-Type"), "multipart/form-data")
 
@@ -136,3 +330,503 @@ func createVM(t *testing.T) modulestest.VM {
 
 	return vm
 }
+
+func TestProxyAuth(t *testing.T) {
+	t.Parallel()
+	vm := modulestest.New(t, js.WithInitial(func(rt *sobek.Runtime) {
+		client := http.Client{Transport: &http.Transport{
+			Proxy:           ski.ProxyFromRequest,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}}
+		instance, _ := (&Http{&client}).Instantiate(rt)
+		_ = rt.Set("http", instance)
+	}))
+
+	target := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, "tunnel ok")
+	}))
+	t.Cleanup(target.Close)
+
+	const credentials = "Basic dXNlcjpwQHNz" // user:p@ss
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Proxy-Authorization") != credentials {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		if r.Method != http.MethodConnect {
+			_, _ = fmt.Fprint(w, "proxy ok")
+			return
+		}
+		upstream, err := net.Dial("tcp", r.Host)
+		if !assert.NoError(t, err) {
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if !assert.NoError(t, err) {
+			return
+		}
+		_, _ = fmt.Fprint(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+		go func() {
+			_, _ = io.Copy(upstream, conn)
+			_ = upstream.Close()
+		}()
+		_, _ = io.Copy(conn, upstream)
+		_ = conn.Close()
+	}))
+	t.Cleanup(proxy.Close)
+
+	_ = vm.Runtime().Set("target", target.URL)
+	_ = vm.Runtime().Set("proxyURL", proxy.URL)
+	_ = vm.Runtime().Set("proxyHost", strings.TrimPrefix(proxy.URL, "http://"))
+
+	testCase := []string{
+		`assert.equal(http.get("http://example.com", { proxy: proxyURL }).status, 407);`,
+		`assert.equal(http.get("http://example.com", { proxy: "http://user:p%40ss@" + proxyHost }).text(), "proxy ok");`,
+		`const viaProxy = http.get("http://example.com", { proxy: proxyURL, proxyAuth: "user:p@ss" });
+		 assert.equal(viaProxy.text(), "proxy ok");
+		 assert.equal(viaProxy.proxy, "http://user:xxxxx@" + proxyHost);
+		 assert.equal(http.get(target, { proxy: proxyURL, proxyAuth: "user:p@ss" }).proxy, "http://user:xxxxx@" + proxyHost);`,
+		`assert.equal(http.get("http://example.com", {
+			proxy: proxyURL,
+			proxyAuth: { username: "user", password: "p@ss" },
+		 }).text(), "proxy ok");`,
+		`assert.equal(http.get(target, { proxy: proxyURL, proxyAuth: "user:p@ss" }).text(), "tunnel ok");`,
+		`try {
+			http.get(target, { proxy: proxyURL, proxyAuth: "user:wrong" });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("Proxy Authentication Required"), e.toString());
+		 }`,
+		`try {
+			http.get(target, { proxyAuth: "user:p@ss" });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("proxyAuth requires the proxy option"), e.toString());
+		 }`,
+		`try {
+			http.get(target, { proxy: proxyURL, proxyAuth: { password: "p@ss" } });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("proxyAuth username is required"), e.toString());
+		 }`,
+	}
+
+	for i, s := range testCase {
+		t.Run(fmt.Sprintf("Script%v", i), func(t *testing.T) {
+			_, err := vm.Runtime().RunString(fmt.Sprintf(`{%s}`, s))
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestAuth(t *testing.T) {
+	t.Parallel()
+	vm := createVM(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, strings.Join(r.Header.Values("Authorization"), ","))
+	}))
+	t.Cleanup(ts.Close)
+	_ = vm.Runtime().Set("authURL", ts.URL)
+
+	testCase := []string{
+		`assert.equal(http.get(authURL, { auth: { type: "basic", username: "user", password: "p@ss" } }).text(),
+			"Basic dXNlcjpwQHNz");`,
+		`assert.equal(http.get(authURL, { auth: { type: "Basic", username: "用户", password: "密码" } }).text(),
+			"Basic 55So5oi3OuWvhueggQ==");`,
+		`assert.equal(http.get(authURL, { auth: { type: "basic", username: "user" } }).text(), "Basic dXNlcjo=");`,
+		`assert.equal(http.get(authURL, { auth: { type: "bearer", token: "t0ken" } }).text(), "Bearer t0ken");`,
+		`assert.equal(http.get(authURL, {
+			headers: { "authorization": "Bearer old" },
+			auth: { type: "bearer", token: "new" },
+		 }).text(), "Bearer new");`,
+		`try {
+			http.get(authURL, { auth: { type: "basic", password: "p@ss" } });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("options auth username is required"), e.toString());
+		 }`,
+		`try {
+			http.get(authURL, { auth: { type: "basic", username: "a:b" } });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("options auth username must not contain colon"), e.toString());
+		 }`,
+		`try {
+			http.get(authURL, { auth: { type: "bearer" } });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("options auth token is required"), e.toString());
+		 }`,
+		`try {
+			http.get(authURL, { auth: { type: "digest" } });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("options auth type digest is not supported"), e.toString());
+		 }`,
+		`try {
+			http.get(authURL, { auth: "user:pass" });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("options auth expected object"), e.toString());
+		 }`,
+	}
+
+	for i, s := range testCase {
+		t.Run(fmt.Sprintf("Script%v", i), func(t *testing.T) {
+			_, err := vm.Runtime().RunString(fmt.Sprintf(`{%s}`, s))
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	t.Parallel()
+	vm := createVM(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Content-Type", r.Header.Get("Content-Type"))
+		_, _ = io.Copy(w, r.Body)
+	}))
+	t.Cleanup(ts.Close)
+	_ = vm.Runtime().Set("ndjsonURL", ts.URL)
+
+	testCase := []string{
+		`const res = http.post(ndjsonURL, { body: [{ id: 1, name: "foo" }, { id: 2, tags: ["a"] }, "str", 3], type: "ndjson" });
+		 assert.equal(res.headers.get("x-content-type"), "application/x-ndjson");
+		 const lines = res.text().split("\n");
+		 assert.equal(lines.length, 5);
+		 assert.equal(JSON.parse(lines[0]), { id: 1, name: "foo" });
+		 assert.equal(JSON.parse(lines[1]), { id: 2, tags: ["a"] });
+		 assert.equal(lines.slice(2), ['"str"', "3", ""]);`,
+		`const res = http.post(ndjsonURL, {
+			body: [{ id: 1 }],
+			type: "ndjson",
+			headers: { "content-type": "application/jsonl" },
+		 });
+		 assert.equal(res.headers.get("x-content-type"), "application/jsonl");
+		 assert.equal(res.text(), '{"id":1}\n');`,
+		`assert.equal(http.post(ndjsonURL, { body: [], type: "ndjson" }).text(), "");`,
+		`assert.equal(http.post(ndjsonURL, { body: "raw", type: undefined }).text(), "raw");
+		 assert.equal(http.post(ndjsonURL, { body: "raw", type: null }).text(), "raw");`,
+		`try {
+			http.post(ndjsonURL, { body: { id: 1 }, type: "ndjson" });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("options type ndjson expected array body"), e.toString());
+		 }`,
+	}
+
+	for i, s := range testCase {
+		t.Run(fmt.Sprintf("Script%v", i), func(t *testing.T) {
+			_, err := vm.Runtime().RunString(fmt.Sprintf(`{%s}`, s))
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMultipartStream(t *testing.T) {
+	t.Parallel()
+	vm := createVM(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mr, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			n, _ := io.Copy(io.Discard, part)
+			_, _ = fmt.Fprintf(w, "%s:%s:%s:%d;", part.FormName(), part.FileName(),
+				part.Header.Get("Content-Type"), n)
+		}
+		_, _ = fmt.Fprintf(w, "%d", r.ContentLength)
+	}))
+	t.Cleanup(ts.Close)
+
+	root := t.TempDir()
+	path := filepath.Join(root, "large.bin")
+	assert.NoError(t, os.WriteFile(path, bytes.Repeat([]byte("0123456789abcdef"), 512<<10), 0o600))
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	assert.NoError(t, os.WriteFile(outside, []byte("secret"), 0o600))
+	assert.NoError(t, os.Symlink(outside, filepath.Join(root, "link.txt")))
+	_ = vm.Runtime().Set("streamURL", ts.URL)
+	_ = vm.Runtime().Set("largeFile", path)
+	_ = vm.Runtime().Set("outsideFile", outside)
+	// the path is only allowed in the root
+	noRoot, _ := new(FormData).Instantiate(vm.Runtime())
+	_ = vm.Runtime().Set("NoRootFormData", noRoot)
+	rooted, _ := (&FormData{Root: root}).Instantiate(vm.Runtime())
+	_ = vm.Runtime().Set("FormData", rooted)
+
+	testCase := []string{
+		`const fd = new FormData({ file: { path: largeFile, type: "application/octet-stream" }, name: "foo" });
+		 assert.equal(http.post(streamURL, { body: fd }).text(),
+			"file:large.bin:application/octet-stream:8388608;name:::3;-1");`,
+		`const fd = new FormData();
+		 fd.append("file", { path: largeFile, filename: "upload.bin" });
+		 fd.append("data", { data: "bar" });
+		 assert.equal(http.post(streamURL, { body: fd }).text(),
+			"file:upload.bin:application/octet-stream:8388608;data:blob:application/octet-stream:3;-1");`,
+		`try {
+			new FormData({ file: { path: largeFile + ".missing" } });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("file path"), e.toString());
+		 }`,
+		`const fd = new FormData({ file: { path: "large.bin" } });
+		 assert.equal(http.post(streamURL, { body: fd }).text(),
+			"file:large.bin:application/octet-stream:8388608;-1");`,
+		`for (const path of [outsideFile, "../" + outsideFile.split("/").slice(-2).join("/"), "link.txt"]) {
+			try {
+				new FormData({ file: { path } });
+				assert.true(false, path);
+			} catch (e) {
+				assert.true(e.toString().includes("is outside the root"), e.toString());
+			}
+		 }`,
+		`try {
+			new NoRootFormData().append("file", { path: largeFile });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("file path is not allowed, the FormData root is not set"), e.toString());
+		 }`,
+	}
+
+	for i, s := range testCase {
+		t.Run(fmt.Sprintf("Script%v", i), func(t *testing.T) {
+			_, err := vm.Runtime().RunString(fmt.Sprintf(`{%s}`, s))
+			assert.NoError(t, err)
+		})
+	}
+
+	// the writer starts on the first read, the unsent body reads no file
+	reader := &countReader{Reader: strings.NewReader("foo")}
+	data := &formData{keys: []string{"file"}, data: map[string][]any{"file": {fileData{reader: reader, filename: "a"}}}}
+	body, err := multipartBody(data, make(map[string]string))
+	if assert.NoError(t, err) {
+		assert.NoError(t, body.(io.Closer).Close())
+		_, err = body.Read(make([]byte, 1))
+		assert.ErrorIs(t, err, io.ErrClosedPipe)
+		assert.Zero(t, reader.reads.Load())
+	}
+	body, err = multipartBody(data, make(map[string]string))
+	if assert.NoError(t, err) {
+		content, err := io.ReadAll(body)
+		assert.NoError(t, err)
+		assert.Contains(t, string(content), "foo")
+		assert.NotZero(t, reader.reads.Load())
+	}
+}
+
+func TestRequest(t *testing.T) {
+	t.Parallel()
+	vm := createVM(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"method":  r.Method,
+			"host":    r.Host,
+			"multi":   r.Header.Values("X-Multi"),
+			"single":  r.Header.Get("X-Single"),
+			"body":    string(body),
+			"trailer": r.Trailer.Get("X-Checksum"),
+		})
+	}))
+	t.Cleanup(ts.Close)
+	_ = vm.Runtime().Set("requestURL", ts.URL)
+
+	testCase := []string{
+		`const res = http.request({
+			url: requestURL,
+			method: "purge",
+			host: "example.com",
+			headers: [["X-Multi", "1"], ["x-multi", "2"], ["X-Single", "a"], ["X-Multi", "3"]],
+		 }).json();
+		 assert.equal(res.method, "purge");
+		 assert.equal(res.host, "example.com");
+		 assert.equal(res.multi, ["1", "2", "3"]);
+		 assert.equal(res.single, "a");`,
+		`const res = http.request({ url: requestURL, headers: { Host: "example.org" } }).json();
+		 assert.equal(res.method, "GET");
+		 assert.equal(res.host, "example.org");`,
+		`const res = http.request({
+			url: requestURL,
+			method: "POST",
+			body: "foo",
+			trailers: { "X-Checksum": "abc" },
+		 }).json();
+		 assert.equal(res.body, "foo");
+		 assert.equal(res.trailer, "abc");`,
+		`const res = http.request(requestURL, { method: "post", headers: [["X-Multi", "1"]] }).json();
+		 assert.equal(res.method, "POST");
+		 assert.equal(res.multi, ["1"]);`,
+		`try {
+			http.request({ url: requestURL, headers: [["X-Multi"]] });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("expected [name, value] pair"), e.toString());
+		 }`,
+		`try {
+			http.request({ url: requestURL, trailers: { "X-Checksum": "abc" } });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("options trailers requires the body"), e.toString());
+		 }`,
+	}
+
+	for i, s := range testCase {
+		t.Run(fmt.Sprintf("Script%v", i), func(t *testing.T) {
+			_, err := vm.Runtime().RunString(fmt.Sprintf(`{%s}`, s))
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestThrowOnError(t *testing.T) {
+	t.Parallel()
+	vm := createVM(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.Error(w, "page not found", http.StatusNotFound)
+			return
+		}
+		_, _ = fmt.Fprint(w, "ok")
+	}))
+	t.Cleanup(ts.Close)
+	_ = vm.Runtime().Set("statusURL", ts.URL)
+
+	testCase := []string{
+		`const res = http.get(statusURL + "/missing");
+		 assert.equal(res.status, 404);
+		 assert.equal(res.text(), "page not found\n");`,
+		`try {
+			http.get(statusURL + "/missing", { throwOnError: true });
+			assert.true(false);
+		 } catch (e) {
+			assert.equal(e.status, 404);
+			assert.equal(e.body, "page not found\n");
+			assert.true(e.toString().includes("404 Not Found"), e.toString());
+			assert.true(e.toString().includes("page not found"), e.toString());
+		 }`,
+		`assert.equal(http.get(statusURL, { throwOnError: true }).text(), "ok");`,
+		`assert.equal(http.get(statusURL + "/missing", { throwOnError: false }).status, 404);`,
+		`try {
+			http.all([statusURL, [statusURL + "/missing", { throwOnError: true }]]);
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("request 1:"), e.toString());
+			assert.true(e.toString().includes("404 Not Found"), e.toString());
+		 }`,
+		`const settled = http.allSettled([[statusURL + "/missing", { throwOnError: true }]]);
+		 assert.equal(settled[0].status, "rejected");
+		 assert.equal(settled[0].reason.status, 404);
+		 assert.equal(settled[0].reason.body, "page not found\n");
+		 assert.true(settled[0].reason.toString().includes("404 Not Found"), settled[0].reason.toString());`,
+	}
+
+	for i, s := range testCase {
+		t.Run(fmt.Sprintf("Script%v", i), func(t *testing.T) {
+			_, err := vm.Runtime().RunString(fmt.Sprintf(`{%s}`, s))
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestAcceptEncoding(t *testing.T) {
+	t.Parallel()
+	vm := modulestest.New(t, js.WithInitial(func(rt *sobek.Runtime) {
+		instance, _ := (&Http{ski.NewFetcher(ski.FetchOptions{})}).Instantiate(rt)
+		_ = rt.Set("http", instance)
+	}))
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Accept-Encoding", r.Header.Get("Accept-Encoding"))
+		w.Header().Set("Content-Encoding", "gzip")
+		gw := gzip.NewWriter(w)
+		_, _ = gw.Write([]byte("gzip content"))
+		_ = gw.Close()
+	}))
+	t.Cleanup(ts.Close)
+	_ = vm.Runtime().Set("url", ts.URL)
+
+	testCase := []string{
+		`const res = http.get(url);
+		 assert.equal(res.text(), "gzip content");
+		 assert.equal(res.headers.get("content-encoding"), null);`,
+		`const res = http.get(url, { acceptEncoding: "identity" });
+		 assert.equal(res.headers.get("x-accept-encoding"), "identity");
+		 assert.equal(res.headers.get("content-encoding"), "gzip");
+		 const raw = new Uint8Array(res.body);
+		 assert.equal(raw[0], 0x1f);
+		 assert.equal(raw[1], 0x8b);`,
+		`const res = http.get(url, { acceptEncoding: ["zstd", "deflate"], headers: { "accept-encoding": "gzip" } });
+		 assert.equal(res.headers.get("x-accept-encoding"), "zstd, deflate");
+		 assert.equal(res.headers.get("content-encoding"), "gzip");`,
+		`const res = http.get(url, { acceptEncoding: "zstd, gzip" });
+		 assert.equal(res.headers.get("x-accept-encoding"), "zstd, gzip");
+		 assert.equal(res.text(), "gzip content");`,
+		`try {
+			http.get(url, { acceptEncoding: [] });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("options acceptEncoding is invalid"), e.toString());
+		 }`,
+	}
+
+	for i, s := range testCase {
+		t.Run(fmt.Sprintf("Script%v", i), func(t *testing.T) {
+			_, err := vm.Runtime().RunString(fmt.Sprintf(`{%s}`, s))
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestStrictURL(t *testing.T) {
+	t.Parallel()
+	vm := createVM(t)
+
+	testCase := []string{
+		`try {
+			http.get("/relative", { strictURL: true });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("invalid request URL: \"/relative\" is not absolute"), e.toString());
+		 }`,
+		`try {
+			http.get("ftp://example.com/file", { strictURL: true });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("unsupported scheme \"ftp\""), e.toString());
+		 }`,
+		`try {
+			fetch("ftp://example.com/file", { strictURL: true });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("unsupported scheme \"ftp\""), e.toString());
+		 }`,
+		`const res = http.get(url, { strictURL: true });
+		 assert.equal(res.status, 200);`,
+	}
+
+	for i, s := range testCase {
+		t.Run(fmt.Sprintf("Script%v", i), func(t *testing.T) {
+			_, err := vm.Runtime().RunString(fmt.Sprintf(`{%s}`, s))
+			assert.NoError(t, err)
+		})
+	}
+}
+
+type countReader struct {
+	io.Reader
+	reads atomic.Int32
+}
+
+func (r *countReader) Read(p []byte) (int, error) {
+	r.reads.Add(1)
+	return r.Reader.Read(p)
+}
@@ -1,18 +1,70 @@
 package http
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/grafana/sobek"
+	"github.com/shiroyk/ski"
 	"github.com/shiroyk/ski/js"
+	"golang.org/x/net/html/charset"
 )
 
 var errBodyAlreadyRead = errors.New("body stream already read")
 
+var (
+	decodeCharsetKey byte
+	encodingKey      byte
+)
+
+// decodeCharset transcodes the text body to UTF-8 unless the request option decodeCharset is false,
+// the charset is determined by the Content-Type header or sniffed from the body unless
+// the sniffing is disabled by ski.WithCharsetSniff. The request option encoding forces
+// the charset regardless of both. Otherwise, the body is returned unchanged.
+func decodeCharset(res *http.Response, data []byte) ([]byte, error) {
+	if res.Request == nil || len(data) == 0 {
+		return data, nil
+	}
+	ctx := res.Request.Context()
+	if label, ok := ctx.Value(&encodingKey).(string); ok {
+		return decodeLabel(label, data)
+	}
+	if decode, ok := ctx.Value(&decodeCharsetKey).(bool); ok && !decode {
+		return data, nil
+	}
+	contentType := res.Header.Get("Content-Type")
+	if !ski.CharsetSniffFromContext(ctx) {
+		_, params, _ := mime.ParseMediaType(contentType)
+		if _, name := charset.Lookup(params["charset"]); name == "" {
+			// trust the header only, no or unknown charset
+			return data, nil
+		}
+		return decodeLabel(params["charset"], data)
+	}
+	reader, err := charset.NewReader(bytes.NewReader(data), contentType)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(reader)
+}
+
+// decodeLabel transcodes the data from the charset label to UTF-8.
+func decodeLabel(label string, data []byte) ([]byte, error) {
+	reader, err := charset.NewReaderLabel(label, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(reader)
+}
+
 func defineGetter(r *sobek.Runtime, o *sobek.Object, name string, v func() any) {
 	_ = o.DefineAccessorProperty(name, r.ToValue(func(sobek.FunctionCall) sobek.Value {
 		return r.ToValue(v())
@@ -21,7 +73,11 @@ func defineGetter(r *sobek.Runtime, o *sobek.Object, name string, v func() any)
 
 // NewResponse returns a new Response
 func NewResponse(rt *sobek.Runtime, res *http.Response) sobek.Value {
-	var bodyUsed bool
+	var (
+		bodyUsed bool
+		size     atomic.Int64
+	)
+	size.Store(-1)
 	js.OnDone(rt, func() {
 		if !bodyUsed {
 			res.Body.Close()
@@ -33,37 +89,109 @@ func NewResponse(rt *sobek.Runtime, res *http.Response) sobek.Value {
 		}
 		bodyUsed = true
 		defer res.Body.Close()
-		data, err := io.ReadAll(res.Body)
+		data, err := ski.ReadBody(res.Body)
 		if err != nil {
 			js.Throw(rt, err)
 		}
+		size.Store(int64(len(data)))
 		return data
 	}
 
 	object := rt.NewObject()
 	defineGetter(rt, object, "body", func() any { return rt.NewArrayBuffer(readBody()) })
 	defineGetter(rt, object, "bodyUsed", func() any { return bodyUsed })
-	defineGetter(rt, object, "headers", func() any { return joinHeader(res.Header) })
+	defineGetter(rt, object, "headers", func() any { return newHeaders(rt, res.Header) })
+	defineGetter(rt, object, "links", func() any { return parseLink(res) })
+	defineGetter(rt, object, "timing", func() any { return timing(res) })
+	defineGetter(rt, object, "proxy", func() any { return proxyURL(res) })
+	defineGetter(rt, object, "protocol", func() any { return res.Proto })
+	defineGetter(rt, object, "url", func() any { return responseURL(res) })
+	defineGetter(rt, object, "redirects", func() any { return redirects(res) })
 	defineGetter(rt, object, "status", func() any { return res.StatusCode })
-	defineGetter(rt, object, "statusText", func() any { return res.Status })
+	defineGetter(rt, object, "statusText", func() any { return statusText(res) })
+	defineGetter(rt, object, "size", func() any { return bodySize(res, size.Load()) })
+	defineGetter(rt, object, "contentType", func() any { return contentType(res) })
 	defineGetter(rt, object, "ok", func() any {
 		return res.StatusCode >= 200 && res.StatusCode < 300
 	})
-	_ = object.Set("text", func(sobek.FunctionCall) sobek.Value { return rt.ToValue(string(readBody())) })
+	readText := func() []byte {
+		data, err := decodeCharset(res, readBody())
+		if err != nil {
+			js.Throw(rt, err)
+		}
+		return data
+	}
+	_ = object.Set("text", func(sobek.FunctionCall) sobek.Value { return rt.ToValue(string(readText())) })
 	_ = object.Set("json", func(call sobek.FunctionCall) sobek.Value {
-		var data any
-		if err := json.Unmarshal(readBody(), &data); err != nil {
+		data, err := parseJSON(readText())
+		if err != nil {
 			js.Throw(rt, err)
 		}
 		return rt.ToValue(data)
 	})
 	_ = object.Set("arrayBuffer", func(sobek.FunctionCall) sobek.Value { return rt.ToValue(rt.NewArrayBuffer(readBody())) })
+	// bytes returns the Uint8Array of the undecoded body, the decodeCharset option is ignored
+	_ = object.Set("bytes", func(sobek.FunctionCall) sobek.Value {
+		value, err := newUint8Array(rt, readBody())
+		if err != nil {
+			js.Throw(rt, err)
+		}
+		return value
+	})
+	_ = object.Set("stream", func(sobek.FunctionCall) sobek.Value {
+		if bodyUsed {
+			js.Throw(rt, errBodyAlreadyRead)
+		}
+		bodyUsed = true
+		js.OnDone(rt, func() { _ = res.Body.Close() })
+		return newBodyReader(rt, res.Body)
+	})
+	return object
+}
+
+// defaultChunkSize the default chunk size of the bodyReader read
+const defaultChunkSize = 32 * 1024
+
+// newBodyReader returns the synchronous reader to process the large body chunk by chunk,
+// read(size) returns the Uint8Array chunk or null if the body is drained.
+func newBodyReader(rt *sobek.Runtime, body io.ReadCloser) *sobek.Object {
+	object := rt.NewObject()
+	_ = object.Set("read", func(call sobek.FunctionCall) sobek.Value {
+		size := defaultChunkSize
+		if v := call.Argument(0); !sobek.IsUndefined(v) {
+			if size = int(v.ToInteger()); size <= 0 {
+				js.Throw(rt, fmt.Errorf("read size %d must be positive", size))
+			}
+		}
+		buffer := make([]byte, size)
+		n, err := io.ReadFull(body, buffer)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			if errors.Is(err, io.EOF) {
+				return sobek.Null()
+			}
+			js.Throw(rt, err)
+		}
+		value, err := rt.New(rt.Get("Uint8Array"), rt.ToValue(rt.NewArrayBuffer(buffer[:n])))
+		if err != nil {
+			js.Throw(rt, err)
+		}
+		return value
+	})
+	_ = object.Set("close", func() {
+		if err := body.Close(); err != nil {
+			js.Throw(rt, err)
+		}
+	})
 	return object
 }
 
 // NewAsyncResponse returns a new async Response
 func NewAsyncResponse(rt *sobek.Runtime, res *http.Response) sobek.Value {
-	var bodyUsed bool
+	var (
+		bodyUsed bool
+		size     atomic.Int64
+	)
+	size.Store(-1)
 	js.OnDone(rt, func() {
 		if !bodyUsed {
 			res.Body.Close()
@@ -77,10 +205,11 @@ func NewAsyncResponse(rt *sobek.Runtime, res *http.Response) sobek.Value {
 		}
 		bodyUsed = true
 		defer res.Body.Close()
-		data, err := io.ReadAll(res.Body)
+		data, err := ski.ReadBody(res.Body)
 		if err != nil {
 			return nil, err
 		}
+		size.Store(int64(len(data)))
 		return data, nil
 	}
 
@@ -91,15 +220,30 @@ func NewAsyncResponse(rt *sobek.Runtime, res *http.Response) sobek.Value {
 		return NewReadableStream(res.Body, rt, &bodyUsed)
 	})
 	defineGetter(rt, object, "bodyUsed", func() any { return bodyUsed })
-	defineGetter(rt, object, "headers", func() any { return joinHeader(res.Header) })
+	defineGetter(rt, object, "headers", func() any { return newHeaders(rt, res.Header) })
+	defineGetter(rt, object, "links", func() any { return parseLink(res) })
+	defineGetter(rt, object, "timing", func() any { return timing(res) })
+	defineGetter(rt, object, "proxy", func() any { return proxyURL(res) })
+	defineGetter(rt, object, "protocol", func() any { return res.Proto })
+	defineGetter(rt, object, "url", func() any { return responseURL(res) })
+	defineGetter(rt, object, "redirects", func() any { return redirects(res) })
 	defineGetter(rt, object, "status", func() any { return res.StatusCode })
-	defineGetter(rt, object, "statusText", func() any { return res.Status })
+	defineGetter(rt, object, "statusText", func() any { return statusText(res) })
+	defineGetter(rt, object, "size", func() any { return bodySize(res, size.Load()) })
+	defineGetter(rt, object, "contentType", func() any { return contentType(res) })
 	defineGetter(rt, object, "ok", func() any {
 		return res.StatusCode >= 200 && res.StatusCode < 300
 	})
+	readText := func() ([]byte, error) {
+		data, err := readBody()
+		if err != nil {
+			return nil, err
+		}
+		return decodeCharset(res, data)
+	}
 	_ = object.Set("text", func(sobek.FunctionCall) sobek.Value {
 		return rt.ToValue(js.NewPromise(rt, func() (any, error) {
-			data, err := readBody()
+			data, err := readText()
 			if err != nil {
 				return nil, err
 			}
@@ -108,15 +252,11 @@ func NewAsyncResponse(rt *sobek.Runtime, res *http.Response) sobek.Value {
 	})
 	_ = object.Set("json", func(sobek.FunctionCall) sobek.Value {
 		return rt.ToValue(js.NewPromise(rt, func() (any, error) {
-			data, err := readBody()
+			data, err := readText()
 			if err != nil {
 				return nil, err
 			}
-			var j any
-			if err = json.Unmarshal(data, &j); err != nil {
-				return nil, err
-			}
-			return j, err
+			return parseJSON(data)
 		}))
 	})
 	_ = object.Set("arrayBuffer", func(sobek.FunctionCall) sobek.Value {
@@ -128,9 +268,115 @@ func NewAsyncResponse(rt *sobek.Runtime, res *http.Response) sobek.Value {
 			return rt.NewArrayBuffer(data), nil
 		}))
 	})
+	// bytes returns the Uint8Array of the undecoded body, the decodeCharset option is ignored
+	_ = object.Set("bytes", func(sobek.FunctionCall) sobek.Value {
+		return rt.ToValue(js.NewPromise(rt, readBody, func(data []byte, err error) (any, error) {
+			if err != nil {
+				return nil, err
+			}
+			return newUint8Array(rt, data)
+		}))
+	})
 	return object
 }
 
+// newUint8Array returns the Uint8Array of the data, it must be called on the runtime goroutine.
+func newUint8Array(rt *sobek.Runtime, data []byte) (sobek.Value, error) {
+	return rt.New(rt.Get("Uint8Array"), rt.ToValue(rt.NewArrayBuffer(data)))
+}
+
+// jsonSnippetSize the maximum size of the body snippet in the JSON error
+const jsonSnippetSize = 64
+
+// parseJSON parses the JSON body, the error contains a snippet of the body.
+func parseJSON(data []byte) (any, error) {
+	var ret any
+	if err := json.Unmarshal(data, &ret); err != nil {
+		snippet := data
+		if len(snippet) > jsonSnippetSize {
+			snippet = append(snippet[:jsonSnippetSize:jsonSnippetSize], "..."...)
+		}
+		return nil, fmt.Errorf("invalid JSON body %q: %w", snippet, err)
+	}
+	return ret, nil
+}
+
+// timing returns the request phases durations in milliseconds,
+// returns nil if the fetcher timing is disabled.
+func timing(res *http.Response) any {
+	t, ok := ski.TimingFromResponse(res)
+	if !ok {
+		return nil
+	}
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+	return map[string]float64{
+		"dns":     ms(t.DNS),
+		"connect": ms(t.Connect),
+		"tls":     ms(t.TLS),
+		"ttfb":    ms(t.TTFB),
+		"total":   ms(t.Total),
+	}
+}
+
+// proxyURL returns the proxy URL used by the response with the password redacted,
+// returns nil if no proxy.
+func proxyURL(res *http.Response) any {
+	if proxy := ski.ProxyFromResponse(res); proxy != nil {
+		return proxy.Redacted()
+	}
+	return nil
+}
+
+// statusText returns the reason phrase of the status line, eg: "Not Found",
+// the http.StatusText if the server sent none.
+func statusText(res *http.Response) string {
+	if _, reason, ok := strings.Cut(res.Status, " "); ok && reason != "" {
+		return reason
+	}
+	return http.StatusText(res.StatusCode)
+}
+
+// bodySize returns the decoded body size once the body is read, before that the Content-Length
+// of the uncompressed response, returns nil if unknown. The size of the streamed body is unknown.
+func bodySize(res *http.Response, read int64) any {
+	if read >= 0 {
+		return read
+	}
+	if res.ContentLength >= 0 {
+		return res.ContentLength
+	}
+	return nil
+}
+
+// contentType returns the lower case media type of the Content-Type without the parameters,
+// eg: "text/html" of "text/html; charset=UTF-8"
+func contentType(res *http.Response) string {
+	value := res.Header.Get("Content-Type")
+	if mediaType, _, err := mime.ParseMediaType(value); err == nil {
+		return mediaType
+	}
+	mediaType, _, _ := strings.Cut(value, ";")
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
+// responseURL returns the final URL after the redirects
+func responseURL(res *http.Response) string {
+	if res.Request == nil {
+		return ""
+	}
+	return res.Request.URL.String()
+}
+
+// redirects returns the URLs redirected from before the final response
+func redirects(res *http.Response) []any {
+	urls := ski.Redirects(res)
+	ret := make([]any, len(urls))
+	for i, u := range urls {
+		ret[i] = u
+	}
+	return ret
+}
+
 func joinHeader(header http.Header) map[string]string {
 	h := make(map[string]string, len(header))
 	for k, vs := range header {
@@ -139,6 +385,20 @@ func joinHeader(header http.Header) map[string]string {
 	return h
 }
 
+// parseLink parses the Link header, relative links are resolved against the request URL.
+func parseLink(res *http.Response) map[string]string {
+	links := ski.ParseLink(res.Header)
+	if res.Request == nil || res.Request.URL == nil {
+		return links
+	}
+	for rel, link := range links {
+		if u, err := res.Request.URL.Parse(link); err == nil {
+			links[rel] = u.String()
+		}
+	}
+	return links
+}
+
 // NewReadableStream ReadableStream API
 // https://developer.mozilla.org/en-US/docs/Web/API/ReadableStream
 func NewReadableStream(body io.ReadCloser, vm *sobek.Runtime, bodyUsed *bool) *sobek.Object {
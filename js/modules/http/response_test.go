@@ -1,14 +1,19 @@
 package http
 
 import (
+	"compress/gzip"
 	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/grafana/sobek"
+	"github.com/shiroyk/ski"
+	"github.com/shiroyk/ski/js"
 	"github.com/shiroyk/ski/js/modulestest"
 	"github.com/stretchr/testify/assert"
 )
@@ -30,6 +35,31 @@ func TestResponse(t *testing.T) {
 			w.Header().Set("Content-Type", "text/plain")
 			_, err := fmt.Fprint(w, `foo`)
 			assert.NoError(t, err)
+		case "/link":
+			w.Header().Set("Link", `</link?page=3>; rel="next", <https://example.com/link?page=9>; rel="last"`)
+			w.Header().Add("Link", `<?page=1>; title="first, prev"; rel="first prev"`)
+		case "/invalid":
+			w.Header().Set("Content-Type", "application/json")
+			_, err := fmt.Fprint(w, `<html>`+strings.Repeat(" ", 100)+`</html>`)
+			assert.NoError(t, err)
+		case "/headers":
+			w.Header().Add("Set-Cookie", "a=1")
+			w.Header().Add("Set-Cookie", "b=2")
+			w.Header().Add("X-Multi", "1")
+			w.Header().Add("X-Multi", "2")
+			w.Header().Set("Location", "/next")
+		case "/latin1":
+			w.Header().Set("Content-Type", "text/plain; charset=iso-8859-1")
+			_, err := w.Write([]byte("caf\xe9"))
+			assert.NoError(t, err)
+		case "/first":
+			http.Redirect(w, r, "/second", http.StatusMovedPermanently)
+		case "/second":
+			http.Redirect(w, r, "/text", http.StatusFound)
+		case "/binary":
+			w.Header().Set("Content-Type", "image/png; charset=iso-8859-1")
+			_, err := w.Write([]byte{0x89, 'P', 'N', 'G', 0x00, 0xe9, 0xff, 0xfe})
+			assert.NoError(t, err)
 		}
 	}))
 
@@ -38,24 +68,97 @@ func TestResponse(t *testing.T) {
 	testCase := []string{
 		`const res = http.get(url+'/array');
 		 assert.true(res.ok);`,
+		`const res = http.get(url+'/latin1', { decodeCharset: true });
+		 assert.equal(res.text(), "café");`,
+		`const res = http.get(url+'/latin1', { decodeCharset: false });
+		 const text = res.text();
+		 assert.true(text !== "café");
+		 assert.equal(text.charCodeAt(3), 0xFFFD);`,
+		`const res = http.get(url+'/latin1');
+		 assert.equal(res.text(), "café");`,
+		`const res = http.get(url+'/first');
+		 assert.equal(res.url, url+'/text');
+		 assert.equal(res.redirects, [url+'/first', url+'/second']);
+		 assert.equal(res.redirects.length, 2);
+		 assert.equal(res.text(), "foo");`,
+		`const res = http.get(url+'/text');
+		 assert.equal(res.url, url+'/text');
+		 assert.equal(res.redirects.length, 0);`,
+		`fetch(url+'/first')
+		 .then(res => {
+			assert.equal(res.url, url+'/text');
+			assert.equal(res.redirects, [url+'/first', url+'/second']);
+		 });`,
+		`const res = http.get(url+'/binary', { decodeCharset: true });
+		 const data = res.bytes();
+		 assert.true(data instanceof Uint8Array);
+		 assert.equal(data, new Uint8Array([0x89, 0x50, 0x4e, 0x47, 0x00, 0xe9, 0xff, 0xfe]));
+		 assert.true(res.bodyUsed);`,
+		`fetch(url+'/binary', { decodeCharset: true })
+		 .then(res => res.bytes())
+		 .then(data => {
+			assert.true(data instanceof Uint8Array);
+			assert.equal(data, new Uint8Array([0x89, 0x50, 0x4e, 0x47, 0x00, 0xe9, 0xff, 0xfe]));
+		 });`,
+		`fetch(url+'/latin1', { decodeCharset: true })
+		 .then(res => res.text())
+		 .then(text => assert.equal(text, "café"));`,
+		`const res = http.get(url+'/invalid');
+		 try {
+			res.json();
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes('invalid JSON body "<html>'), e.toString());
+			assert.true(e.toString().includes('..."'), e.toString());
+			assert.true(!e.toString().includes('</html>'), e.toString());
+		 }`,
+		`fetch(url+'/invalid')
+		 .then(res => res.json())
+		 .catch(e => assert.true(e.toString().includes('invalid JSON body "<html>'), e.toString()));`,
+		`const { headers } = http.get(url+'/headers');
+		 assert.equal(headers.get('location'), '/next');
+		 assert.equal(headers.get('LOCATION'), '/next');
+		 assert.equal(headers['Location'], '/next');
+		 assert.true(headers.has('x-multi'));
+		 assert.true(!headers.has('x-missing'));
+		 assert.equal(headers.get('x-missing'), null);
+		 assert.equal(headers.get('X-Multi'), '1, 2');
+		 assert.equal(headers.getSetCookie(), ['a=1', 'b=2']);
+		 const names = [];
+		 headers.forEach((value, name) => names.push(name + '=' + value));
+		 assert.true(names.includes('x-multi=1, 2'), names);
+		 assert.equal(names, headers.entries().map(([k, v]) => k + '=' + v));
+		 assert.equal(names.filter(n => n.startsWith('set-cookie=')), ['set-cookie=a=1', 'set-cookie=b=2']);
+		 assert.equal(headers.keys().filter(k => k === 'set-cookie').length, 2);
+		 assert.equal(headers.get('set-cookie'), 'a=1, b=2');
+		 let count = 0;
+		 for (const [name, value] of headers) count++;
+		 assert.equal(count, headers.keys().length);
+		 assert.true(!Object.keys(headers).includes('get'));`,
+		`const res = http.get(url+'/link');
+		 assert.equal(res.links.next, url+'/link?page=3');
+		 assert.equal(res.links.last, 'https://example.com/link?page=9');
+		 assert.equal(res.links.first, url+'/link?page=1');
+		 assert.equal(res.links.prev, url+'/link?page=1');
+		 assert.equal(Object.keys(res.links).sort().join(), 'first,last,next,prev');`,
 		`const res = http.get(url+'/json');
 		 assert.equal(res.json(), { "foo": "bar", "test": true });
 		 assert.true(res.bodyUsed);
 		 assert.true(res.ok);
 		 assert.equal(res.status, 200);
-		 assert.equal(res.statusText, "200 OK");
+		 assert.equal(res.statusText, "OK");
 		 assert.equal(res.headers["Content-Type"], "application/json");`,
 		`const res = http.get(url+'/array');
 		 assert.equal(res.json(), [{ "foo": "bar", "test": true }]);
 		 assert.true(res.bodyUsed);
 		 assert.true(res.ok);
 		 assert.equal(res.status, 200);
-		 assert.equal(res.statusText, "200 OK");
+		 assert.equal(res.statusText, "OK");
 		 assert.equal(res.headers["Content-Type"], "application/json");`,
 		`const res = http.get(url+'/text');
 		 assert.true(!res.bodyUsed);
 		 assert.true(res.ok);
-		 assert.equal(res.statusText, "200 OK");
+		 assert.equal(res.statusText, "OK");
 		 assert.equal(res.text(), "foo");
 		 assert.true(res.bodyUsed);
 		 try {
@@ -73,6 +176,24 @@ func TestResponse(t *testing.T) {
 	}
 }
 
+func TestDecodeCharset(t *testing.T) {
+	latin1 := []byte("caf\xe9")
+	decode := func(ctx context.Context) string {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost", nil)
+		res := &http.Response{
+			Header:  http.Header{"Content-Type": {"text/plain; charset=iso-8859-1"}},
+			Request: req,
+		}
+		data, err := decodeCharset(res, latin1)
+		assert.NoError(t, err)
+		return string(data)
+	}
+
+	assert.Equal(t, "café", decode(context.Background()))
+	assert.Equal(t, "café", decode(context.WithValue(context.Background(), &decodeCharsetKey, true)))
+	assert.Equal(t, string(latin1), decode(context.WithValue(context.Background(), &decodeCharsetKey, false)))
+}
+
 func TestAsyncResponse(t *testing.T) {
 	vm := modulestest.New(t, initial)
 
@@ -121,7 +242,7 @@ func TestAsyncResponse(t *testing.T) {
 			assert.true(res.bodyUsed);
 			assert.true(res.ok);
 			assert.equal(res.status, 200);
-			assert.equal(res.statusText, "200 OK");
+			assert.equal(res.statusText, "OK");
 			assert.equal(res.headers["Content-Type"], "text/plain");
 		})()`,
 		`(async () => {
@@ -187,6 +308,282 @@ func (b *testBody) Close() error {
 	return nil
 }
 
+func TestResponseStream(t *testing.T) {
+	const size = 4 << 20
+	vm := modulestest.New(t, js.WithInitial(func(rt *sobek.Runtime) {
+		instance, _ := (&Http{ski.NewFetcher(ski.FetchOptions{MaxBodySize: 1 << 20})}).Instantiate(rt)
+		_ = rt.Set("http", instance)
+	}))
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the client may close the connection once the buffered read exceeds the limit
+		_, _ = w.Write([]byte(strings.Repeat("a", size)))
+	}))
+	t.Cleanup(ts.Close)
+
+	_ = vm.Runtime().Set("url", ts.URL)
+	_ = vm.Runtime().Set("size", size)
+
+	testCase := []string{
+		`const res = http.get(url);
+		 try {
+			res.text();
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("response body too large: exceeds 1048576 bytes"), e.toString());
+		 }`,
+		`const res = http.get(url);
+		 const stream = res.stream();
+		 assert.true(res.bodyUsed);
+		 let total = 0, chunks = 0, chunk;
+		 while ((chunk = stream.read(65536)) !== null) {
+			assert.true(chunk.length <= 65536);
+			assert.equal(chunk[0], 97);
+			total += chunk.length;
+			chunks++;
+		 }
+		 stream.close();
+		 assert.equal(total, size);
+		 assert.equal(chunks, size / 65536);`,
+		`const res = http.get(url);
+		 const stream = res.stream();
+		 try {
+			stream.read(0);
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("read size 0 must be positive"), e.toString());
+		 } finally {
+			stream.close();
+		 }`,
+	}
+
+	for i, s := range testCase {
+		t.Run(fmt.Sprintf("Script%v", i), func(t *testing.T) {
+			_, err := vm.Runtime().RunString(fmt.Sprintf(`{%s}`, s))
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestResponseStatus(t *testing.T) {
+	vm := modulestest.New(t, initial)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/204":
+			w.WriteHeader(http.StatusNoContent)
+		case "/404":
+			http.NotFound(w, r)
+		case "/custom", "/empty":
+			conn, buf, err := w.(http.Hijacker).Hijack()
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer conn.Close()
+			status := "299 Custom Reason"
+			if r.URL.Path == "/empty" {
+				status = "404"
+			}
+			_, _ = buf.WriteString("HTTP/1.1 " + status + "\r\nContent-Length: 0\r\nConnection: close\r\n\r\n")
+			_ = buf.Flush()
+		}
+	}))
+	t.Cleanup(ts.Close)
+	_ = vm.Runtime().Set("url", ts.URL)
+
+	testCase := []string{
+		`const res = http.get(url);
+		 assert.equal(res.status, 200);
+		 assert.true(res.ok);
+		 assert.equal(res.statusText, "OK");`,
+		`const res = http.get(url+'/204');
+		 assert.equal(res.status, 204);
+		 assert.true(res.ok);
+		 assert.equal(res.statusText, "No Content");`,
+		`const res = http.get(url+'/404');
+		 assert.equal(res.status, 404);
+		 assert.true(!res.ok);
+		 assert.equal(res.statusText, "Not Found");`,
+		`const res = http.get(url+'/custom');
+		 assert.equal(res.status, 299);
+		 assert.true(res.ok);
+		 assert.equal(res.statusText, "Custom Reason");`,
+		`const res = http.get(url+'/empty');
+		 assert.equal(res.status, 404);
+		 assert.equal(res.statusText, "Not Found");`,
+		`fetch(url+'/custom').then(res => {
+			assert.true(res.ok);
+			assert.equal(res.statusText, "Custom Reason");
+		 });`,
+	}
+
+	for i, s := range testCase {
+		t.Run(fmt.Sprintf("Script%v", i), func(t *testing.T) {
+			_, err := vm.RunString(context.Background(), fmt.Sprintf(`{%s}`, s))
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestResponseMetadata(t *testing.T) {
+	vm := modulestest.New(t, initial)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprint(w, `{"name":"foo"}`)
+		case "/html":
+			w.Header().Set("Content-Type", "Text/HTML; charset=UTF-8")
+			_, _ = fmt.Fprint(w, "<p>")
+			w.(http.Flusher).Flush()
+			_, _ = fmt.Fprint(w, "你好</p>")
+		case "/gzip":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("Content-Encoding", "gzip")
+			gw := gzip.NewWriter(w)
+			_, _ = gw.Write([]byte(strings.Repeat("a", 100)))
+			_ = gw.Close()
+		case "/none":
+			w.Header()["Content-Type"] = nil
+			_, _ = fmt.Fprint(w, "ok")
+		}
+	}))
+	t.Cleanup(ts.Close)
+	_ = vm.Runtime().Set("url", ts.URL)
+
+	testCase := []string{
+		`const res = http.get(url+'/json');
+		 assert.equal(res.contentType, "application/json");
+		 assert.equal(res.size, 14);
+		 assert.equal(res.json(), { name: "foo" });
+		 assert.equal(res.size, 14);`,
+		`const res = http.get(url+'/html');
+		 assert.equal(res.contentType, "text/html");
+		 assert.equal(res.size, null);
+		 assert.equal(res.text(), "<p>你好</p>");
+		 assert.equal(res.size, 13);`,
+		`const res = http.get(url+'/gzip');
+		 assert.equal(res.contentType, "text/plain");
+		 assert.equal(res.size, null);
+		 assert.equal(res.text().length, 100);
+		 assert.equal(res.size, 100);`,
+		`const res = http.get(url+'/none');
+		 assert.equal(res.contentType, "");
+		 assert.equal(res.text(), "ok");`,
+		`fetch(url+'/html').then(async res => {
+			assert.equal(res.contentType, "text/html");
+			assert.equal(res.size, null);
+			assert.equal(await res.text(), "<p>你好</p>");
+			assert.equal(res.size, 13);
+		 });`,
+	}
+
+	for i, s := range testCase {
+		t.Run(fmt.Sprintf("Script%v", i), func(t *testing.T) {
+			_, err := vm.RunString(context.Background(), fmt.Sprintf(`{%s}`, s))
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestResponseEncoding(t *testing.T) {
+	gbk := "\xc4\xe3\xba\xc3" // 你好
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/misleading":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			_, _ = fmt.Fprint(w, "caf\xe9")
+		case "/gbk":
+			w.Header().Set("Content-Type", "text/plain; charset=iso-8859-1")
+			_, _ = fmt.Fprint(w, gbk)
+		case "/header":
+			w.Header().Set("Content-Type", "text/plain; charset=gbk")
+			_, _ = fmt.Fprint(w, gbk)
+		case "/meta":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = fmt.Fprint(w, `<meta charset="gbk"><p>`+gbk+`</p>`)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	run := func(t *testing.T, vm modulestest.VM, testCase []string) {
+		_ = vm.Runtime().Set("url", ts.URL)
+		for i, s := range testCase {
+			t.Run(fmt.Sprintf("Script%v", i), func(t *testing.T) {
+				_, err := vm.RunString(context.Background(), fmt.Sprintf(`{%s}`, s))
+				assert.NoError(t, err)
+			})
+		}
+	}
+
+	t.Run("sniff", func(t *testing.T) {
+		run(t, modulestest.New(t, initial), []string{
+			`const res = http.get(url+'/misleading', { decodeCharset: true });
+			 assert.equal(res.text().charCodeAt(3), 0xFFFD);`,
+			`const res = http.get(url+'/misleading', { encoding: 'iso-8859-1' });
+			 assert.equal(res.text(), "café");`,
+			`const res = http.get(url+'/misleading', { decodeCharset: false, encoding: 'latin1' });
+			 assert.equal(res.text(), "café");`,
+			`const res = http.get(url+'/gbk', { decodeCharset: true, encoding: 'GBK' });
+			 assert.equal(res.text(), "你好");`,
+			`const res = http.get(url+'/meta', { decodeCharset: true });
+			 assert.equal(res.text(), '<meta charset="gbk"><p>你好</p>');`,
+			`fetch(url+'/misleading', { encoding: 'iso-8859-1' })
+			 .then(res => res.text())
+			 .then(text => assert.equal(text, "café"));`,
+			`try {
+				http.get(url+'/gbk', { encoding: 'unknown' });
+				assert.true(false);
+			 } catch (e) {
+				assert.true(e.toString().includes('options encoding unknown is unknown'), e.toString());
+			 }`,
+		})
+	})
+
+	t.Run("no sniff", func(t *testing.T) {
+		run(t, modulestest.New(t, js.WithInitial(func(rt *sobek.Runtime) {
+			instance, _ := (&Http{ski.NewFetcher(ski.FetchOptions{DisableCharsetSniff: true})}).Instantiate(rt)
+			_ = rt.Set("http", instance)
+		})), []string{
+			`const res = http.get(url+'/meta', { decodeCharset: true });
+			 assert.true(res.text().includes('\uFFFD'));`,
+			`const res = http.get(url+'/header', { decodeCharset: true });
+			 assert.equal(res.text(), "你好");`,
+			`const res = http.get(url+'/meta', { encoding: 'gbk' });
+			 assert.equal(res.text(), '<meta charset="gbk"><p>你好</p>');`,
+		})
+	})
+}
+
+func TestResponseTiming(t *testing.T) {
+	vm := modulestest.New(t, js.WithInitial(func(rt *sobek.Runtime) {
+		instance, _ := (&Http{ski.NewFetcher(ski.FetchOptions{Timing: true})}).Instantiate(rt)
+		_ = rt.Set("http", instance)
+		f, _ := (&Fetch{ski.NewFetcher(ski.FetchOptions{})}).Instantiate(rt)
+		_ = rt.Set("fetch", f)
+	}))
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		_, err := fmt.Fprint(w, "ok")
+		assert.NoError(t, err)
+	}))
+	t.Cleanup(ts.Close)
+	_ = vm.Runtime().Set("url", ts.URL)
+
+	_, err := vm.RunString(context.Background(), `
+		const res = http.get(url);
+		assert.equal(res.timing.total, 0);
+		assert.equal(res.text(), "ok");
+		const { connect, ttfb, total } = res.timing;
+		assert.true(connect > 0, connect);
+		assert.true(ttfb >= 5 && ttfb >= connect, ttfb);
+		assert.true(total >= ttfb, total);
+		assert.equal(res.proxy, null);
+		assert.equal(res.protocol, "HTTP/1.1");
+		fetch(url).then(res => assert.equal(res.timing, null));`)
+	assert.NoError(t, err)
+}
+
 func TestAutoClose(t *testing.T) {
 	vm := modulestest.New(t)
 	body := new(testBody)
@@ -2,6 +2,8 @@ package http
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -18,8 +20,10 @@ type abortController struct {
 	Reason  string
 }
 
-func (c *abortController) Abort() {
-	c.Signal.abort()
+// Abort aborts the signal with the optional reason, the pending requests
+// will be canceled and the thrown error reports the reason.
+func (c *abortController) Abort(reason string) {
+	c.Signal.abortWithReason(reason)
 	c.Aborted = c.Signal.Aborted
 	c.Reason = c.Signal.Reason
 }
@@ -31,7 +35,7 @@ type AbortController struct{}
 func (*AbortController) Instantiate(rt *sobek.Runtime) (sobek.Value, error) {
 	return rt.ToValue(func(call sobek.ConstructorCall, vm *sobek.Runtime) *sobek.Object {
 		signal := new(abortSignal)
-		signal.ctx, signal.cancel = context.WithCancel(js.Context(vm))
+		signal.ctx, signal.cancel = context.WithCancelCause(js.Context(vm))
 		return vm.ToValue(&abortController{Signal: signal}).ToObject(vm)
 	}), nil
 }
@@ -44,19 +48,52 @@ func (*AbortController) Global() {}
 // https://developer.mozilla.org/en-US/docs/Web/API/AbortSignal
 type abortSignal struct {
 	ctx     context.Context
-	cancel  context.CancelFunc
+	cancel  context.CancelCauseFunc
 	once    sync.Once
 	Aborted bool
 	Reason  string
 }
 
-func (s *abortSignal) abort() {
+var (
+	// errSignalTimeout the AbortSignal.timeout signal timed out
+	errSignalTimeout = errors.New("TimeoutError: signal timed out")
+	// errRequestTimeout the request options timeout elapsed
+	errRequestTimeout = errors.New("TimeoutError: request timed out")
+)
+
+// wrap returns the request error with the abort reason if the signal is aborted.
+func (s *abortSignal) wrap(err error) error {
+	if s == nil || err == nil || s.ctx.Err() == nil {
+		return err
+	}
+	if cause := context.Cause(s.ctx); cause != nil && cause != s.ctx.Err() && !errors.Is(err, cause) {
+		return fmt.Errorf("%w: %w", cause, err)
+	}
+	return err
+}
+
+func (s *abortSignal) abort() { s.abortWithReason("") }
+
+// releaseOnDone aborts the signal to release the resources when the runtime is done
+// instead of on the request return, the response body is read afterwards.
+func (s *abortSignal) releaseOnDone(rt *sobek.Runtime) {
+	if s != nil {
+		js.OnDone(rt, s.abort)
+	}
+}
+
+func (s *abortSignal) abortWithReason(reason string) {
 	s.once.Do(func() {
 		s.Aborted = true
-		s.cancel()
-		if err := s.ctx.Err(); err != nil {
-			s.Reason = err.Error()
+		if reason == "" {
+			s.cancel(nil)
+			if err := s.ctx.Err(); err != nil {
+				s.Reason = err.Error()
+			}
+			return
 		}
+		s.cancel(fmt.Errorf("AbortError: %s", reason))
+		s.Reason = reason
 	})
 }
 
@@ -64,16 +101,21 @@ type AbortSignal struct{}
 
 func (*AbortSignal) Instantiate(rt *sobek.Runtime) (sobek.Value, error) {
 	object := rt.NewObject()
-	_ = object.Set("abort", func(_ sobek.FunctionCall) sobek.Value {
+	_ = object.Set("abort", func(call sobek.FunctionCall) sobek.Value {
 		signal := new(abortSignal)
-		signal.ctx, signal.cancel = context.WithCancel(context.Background())
-		signal.abort()
+		signal.ctx, signal.cancel = context.WithCancelCause(context.Background())
+		var reason string
+		if v := call.Argument(0); !sobek.IsUndefined(v) && !sobek.IsNull(v) {
+			reason = v.String()
+		}
+		signal.abortWithReason(reason)
 		return rt.ToValue(signal).ToObject(rt)
 	})
 	_ = object.Set("timeout", func(call sobek.FunctionCall) sobek.Value {
-		timeout := call.Argument(0).ToInteger()
+		timeout := time.Duration(call.Argument(0).ToInteger()) * time.Millisecond
 		signal := new(abortSignal)
-		signal.ctx, signal.cancel = context.WithTimeout(js.Context(rt), time.Duration(timeout))
+		ctx, cancel := context.WithTimeoutCause(js.Context(rt), timeout, errSignalTimeout)
+		signal.ctx, signal.cancel = ctx, func(error) { cancel() }
 		return rt.ToValue(signal).ToObject(rt)
 	})
 	return object, nil
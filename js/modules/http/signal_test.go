@@ -1,13 +1,133 @@
 package http
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/shiroyk/ski/js"
 	"github.com/shiroyk/ski/js/modulestest"
 	"github.com/stretchr/testify/assert"
 )
 
+func TestAbortSignalTimeout(t *testing.T) {
+	vm := createVM(t)
+
+	start := time.Now()
+	_, err := vm.Runtime().RunString(`
+		try {
+			http.post(url, { signal: AbortSignal.timeout(100), body: "sleep300000000" });
+			assert.true(false);
+		} catch (e) {
+			assert.true(e.toString().includes("TimeoutError: signal timed out"), e.toString());
+		}
+		assert.equal(http.post(url, { signal: AbortSignal.timeout(1000), body: "ok" }).text(), "ok");`)
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), 300*time.Millisecond)
+}
+
+func TestRequestTimeout(t *testing.T) {
+	vm := createVM(t)
+
+	start := time.Now()
+	ret, err := vm.RunString(context.Background(), `
+		(async () => {
+			try {
+				http.post(url, { timeout: 100, body: "sleep300000000" });
+				assert.true(false);
+			} catch (e) {
+				assert.true(e.toString().includes("TimeoutError: request timed out after 100ms"), e.toString());
+			}
+			try {
+				await fetch(url, { method: "POST", timeout: 100, body: "sleep300000000" });
+				assert.true(false);
+			} catch (e) {
+				assert.true(e.toString().includes("TimeoutError: request timed out after 100ms"), e.toString());
+			}
+			try {
+				http.post(url, { signal: AbortSignal.timeout(50), timeout: 1000, body: "sleep300000000" });
+				assert.true(false);
+			} catch (e) {
+				assert.true(e.toString().includes("TimeoutError: signal timed out"), e.toString());
+			}
+			try {
+				http.post(url, { signal: AbortSignal.timeout(1000), timeout: 50, body: "sleep300000000" });
+				assert.true(false);
+			} catch (e) {
+				assert.true(e.toString().includes("TimeoutError: request timed out after 50ms"), e.toString());
+			}
+			try {
+				http.get(url, { timeout: 0 });
+				assert.true(false);
+			} catch (e) {
+				assert.true(e.toString().includes("options timeout 0 must be positive"), e.toString());
+			}
+			assert.equal(http.post(url, { timeout: 1000, body: "ok" }).text(), "ok");
+		})()`)
+	if assert.NoError(t, err) {
+		_, err = js.Unwrap(ret)
+		assert.NoError(t, err)
+	}
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestRequestTimeoutBody(t *testing.T) {
+	vm := modulestest.New(t, initial)
+	size := 8 << 20
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(bytes.Repeat([]byte("a"), size))
+	}))
+	t.Cleanup(ts.Close)
+	_ = vm.Runtime().Set("url", ts.URL)
+	_ = vm.Runtime().Set("size", size)
+
+	// the body larger than the buffer is read after the request returns
+	ret, err := vm.RunString(context.Background(), `
+		(async () => {
+			assert.equal(http.get(url, { timeout: 5000 }).text().length, size);
+			const [res] = http.all([[url, { timeout: 5000 }]]);
+			assert.equal(res.arrayBuffer().byteLength, size);
+			const controller = new AbortController();
+			assert.equal(http.get(url, { signal: controller.signal }).text().length, size);
+			assert.true(!controller.aborted);
+			const data = await (await fetch(url, { timeout: 5000 })).arrayBuffer();
+			assert.equal(data.byteLength, size);
+		})()`)
+	if assert.NoError(t, err) {
+		_, err = js.Unwrap(ret)
+		assert.NoError(t, err)
+	}
+}
+
+func TestAbortController(t *testing.T) {
+	vm := createVM(t)
+
+	start := time.Now()
+	ret, err := vm.RunString(context.Background(), `
+		(async () => {
+			const controller = new AbortController();
+			const pending = fetch(url, { signal: controller.signal, body: "sleep300000000" });
+			controller.abort("user cancelled");
+			assert.true(controller.aborted);
+			assert.equal(controller.reason, "user cancelled");
+			try {
+				await pending;
+				assert.true(false, "should be aborted");
+			} catch (e) {
+				assert.true(e.toString().includes("AbortError: user cancelled"), e.toString());
+			}
+		})()`)
+	if assert.NoError(t, err) {
+		_, err = js.Unwrap(ret)
+		assert.NoError(t, err)
+	}
+	assert.Less(t, time.Since(start), 300*time.Millisecond)
+}
+
 func TestAbortSignal(t *testing.T) {
 	vm := modulestest.New(t)
 
@@ -18,6 +138,9 @@ func TestAbortSignal(t *testing.T) {
          assert.true(controller.aborted);`,
 		`const signal = AbortSignal.abort();
          assert.equal(signal.reason, "context canceled");
+         assert.true(signal.aborted);`,
+		`const signal = AbortSignal.abort("stop");
+         assert.equal(signal.reason, "stop");
          assert.true(signal.aborted);`,
 		`const signal = AbortSignal.timeout(100);
          assert.equal(signal.reason, "");
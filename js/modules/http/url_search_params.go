@@ -8,25 +8,26 @@ import (
 	"strings"
 
 	"github.com/grafana/sobek"
-	"github.com/shiroyk/ski"
 	"github.com/shiroyk/ski/js"
 	"github.com/spf13/cast"
 )
 
 // The urlSearchParams defines utility methods to work with the query string of a URL,
-// which can be sent using the http() method and encoding type were set to "application/x-www-form-url".
+// which can be sent using the http() method and encoding type were set to "application/x-www-form-urlencoded".
 // Implement the https://developer.mozilla.org/en-US/docs/Web/API/URLSearchParams
 type urlSearchParams struct {
 	keys []string
 	data map[string][]string
 }
 
-// URLSearchParams Constructor
+// URLSearchParams Constructor, the init is the object or the query string with
+// the optional leading "?", as the browser. The static fromURL parses the query
+// of the URL or the path. eg: URLSearchParams.fromURL(res.url)
 type URLSearchParams struct{}
 
 // Instantiate instance module
 func (*URLSearchParams) Instantiate(rt *sobek.Runtime) (sobek.Value, error) {
-	return rt.ToValue(func(call sobek.ConstructorCall) *sobek.Object {
+	ctor := rt.ToValue(func(call sobek.ConstructorCall) *sobek.Object {
 		params := call.Argument(0)
 
 		var ret urlSearchParams
@@ -36,13 +37,7 @@ func (*URLSearchParams) Instantiate(rt *sobek.Runtime) (sobek.Value, error) {
 		}
 
 		if params.ExportType().Kind() == reflect.String {
-			str := strings.TrimPrefix(params.String(), "?")
-			kvs := strings.Split(str, "&")
-			ret.data = make(map[string][]string, len(kvs))
-			for _, kv := range kvs {
-				k, v, _ := strings.Cut(kv, "=")
-				ret.Append(k, v)
-			}
+			ret = parseQuery(strings.TrimPrefix(params.String(), "?"))
 			return ret.object(rt)
 		}
 
@@ -62,7 +57,16 @@ func (*URLSearchParams) Instantiate(rt *sobek.Runtime) (sobek.Value, error) {
 		}
 
 		return ret.object(rt)
-	}), nil
+	}).ToObject(rt)
+	_ = ctor.Set("fromURL", func(call sobek.FunctionCall) sobek.Value {
+		u, err := url.Parse(call.Argument(0).String())
+		if err != nil {
+			js.Throw(rt, err)
+		}
+		ret := parseQuery(u.RawQuery)
+		return ret.object(rt)
+	})
+	return ctor, nil
 }
 
 // Global it is a global module
@@ -87,23 +91,73 @@ func (u *urlSearchParams) object(rt *sobek.Runtime) *sobek.Object {
 	return obj
 }
 
-// encode encodes the values into “URL encoded” form
-// ("bar=baz&foo=qux") sorted by key.
-func (u *urlSearchParams) encode() string {
+// parseQuery parses the query string, the values of the same key are kept in order.
+func parseQuery(query string) urlSearchParams {
+	kvs := strings.Split(query, "&")
+	ret := urlSearchParams{data: make(map[string][]string, len(kvs))}
+	for _, kv := range kvs {
+		if kv == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(kv, "=")
+		ret.Append(queryUnescape(k), queryUnescape(v))
+	}
+	return ret
+}
+
+// queryUnescape unescapes the query component, returns the raw string if invalid.
+func queryUnescape(s string) string {
+	if unescaped, err := url.QueryUnescape(s); err == nil {
+		return unescaped
+	}
+	return s
+}
+
+// encode encodes the values into the application/x-www-form-urlencoded form
+// ("bar=baz&foo=qux") in the keys order, same as the browser.
+func (u *urlSearchParams) encode() string { return u.encodeWith(false) }
+
+// encodeWith encodes the values, the space is "%20" instead of "+" if rfc3986 is true.
+func (u *urlSearchParams) encodeWith(rfc3986 bool) string {
 	if u.data == nil {
 		return ""
 	}
 	var buf strings.Builder
 	for _, key := range u.keys {
 		vs := u.data[key]
-		keyEscaped := url.QueryEscape(key)
+		keyEscaped := queryEscape(key, rfc3986)
 		for _, v := range vs {
 			if buf.Len() > 0 {
 				buf.WriteByte('&')
 			}
 			buf.WriteString(keyEscaped)
 			buf.WriteByte('=')
-			buf.WriteString(url.QueryEscape(v))
+			buf.WriteString(queryEscape(v, rfc3986))
+		}
+	}
+	return buf.String()
+}
+
+// queryEscape percent-encodes the UTF-8 bytes of s except the unreserved characters.
+// The form encoding keeps the "*-._" and encodes the space as "+", as the WHATWG URL
+// urlencoded serializer. The RFC 3986 encoding keeps the "-._~" and encodes the space as "%20".
+func queryEscape(s string, rfc3986 bool) string {
+	const upperhex = "0123456789ABCDEF"
+	var buf strings.Builder
+	buf.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '-', c == '.', c == '_':
+			buf.WriteByte(c)
+		case c == '*' && !rfc3986, c == '~' && rfc3986:
+			buf.WriteByte(c)
+		case c == ' ' && !rfc3986:
+			buf.WriteByte('+')
+		default:
+			buf.WriteByte('%')
+			buf.WriteByte(upperhex[c>>4])
+			buf.WriteByte(upperhex[c&15])
 		}
 	}
 	return buf.String()
@@ -191,15 +245,28 @@ func (u *urlSearchParams) Set(name, value string) {
 }
 
 // Sort method sorts all key/value pairs contained in this object in place and returns undefined.
+// The sort is stable, the values with the same key keep the relative order.
 func (u *urlSearchParams) Sort() { slices.Sort(u.keys) }
 
 // ToString method of the urlSearchParams interface returns a query string suitable for use in a URL.
-func (u *urlSearchParams) ToString() string {
-	return u.encode()
+// The optional format is "form" (default) encodes the space as "+", or "rfc3986" encodes the space as "%20".
+func (u *urlSearchParams) ToString(format string) (string, error) {
+	switch format {
+	case "", "form":
+		return u.encode(), nil
+	case "rfc3986":
+		return u.encodeWith(true), nil
+	default:
+		return "", fmt.Errorf("unknown format %s, must be form or rfc3986", format)
+	}
 }
 
 // Values method of the urlSearchParams interface returns an iterator allowing iteration through
 // all values contained in this object. The values are string objects.
 func (u *urlSearchParams) Values() [][]string {
-	return ski.MapValues(u.data)
+	values := make([][]string, 0, len(u.keys))
+	for _, key := range u.keys {
+		values = append(values, u.data[key])
+	}
+	return values
 }
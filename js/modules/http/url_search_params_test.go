@@ -22,6 +22,46 @@ func TestURLSearchParams(t *testing.T) {
 		`assert.equal(new URLSearchParams('foo=1&bar=2').toString(), 'foo=1&bar=2')`,
 		`assert.equal(new URLSearchParams('?foo=1&bar=2').toString(), 'foo=1&bar=2')`,
 		`assert.equal(new URLSearchParams('https://example.com?foo=1&bar=2').toString(), 'https%3A%2F%2Fexample.com%3Ffoo=1&bar=2')`,
+		`const fromURL = URLSearchParams.fromURL('https://example.com/path?a=1&b=2&a=3#a=4');
+		 assert.equal(fromURL.keys().join(), 'a,b');
+		 assert.equal(fromURL.getAll('a').join(), '1,3');
+		 assert.equal(fromURL.get('b'), '2');
+		 fromURL.set('b', 'x y');
+		 fromURL.append('c', '4');
+		 assert.equal(fromURL.toString(), 'a=1&a=3&b=x+y&c=4');`,
+		`const multi = new URLSearchParams('?a=1&b=2&a=3');
+		 assert.equal(multi.keys().join(), 'a,b');
+		 assert.equal(multi.getAll('a').join(), '1,3');
+		 assert.equal(multi.getAll('b').join(), '2');
+		 assert.equal(multi.toString(), 'a=1&a=3&b=2');`,
+		`assert.equal(URLSearchParams.fromURL('/search?q=go&page=2').toString(), 'q=go&page=2')`,
+		`const reserved = new URLSearchParams();
+		 reserved.append('a b', 'x&y=z');
+		 reserved.append('名前', '値 ☃');
+		 reserved.append('safe', "*-._~!'()+,/:;?@#[]$%");
+		 assert.equal(reserved.toString(), 'a+b=x%26y%3Dz&%E5%90%8D%E5%89%8D=%E5%80%A4+%E2%98%83&safe=*-._%7E%21%27%28%29%2B%2C%2F%3A%3B%3F%40%23%5B%5D%24%25');
+		 assert.equal(reserved.toString('form'), reserved.toString());
+		 assert.equal(reserved.toString('rfc3986'), 'a%20b=x%26y%3Dz&%E5%90%8D%E5%89%8D=%E5%80%A4%20%E2%98%83&safe=%2A-._~%21%27%28%29%2B%2C%2F%3A%3B%3F%40%23%5B%5D%24%25');
+		 assert.equal(` + "`${reserved}`" + `, reserved.toString());
+		 const decoded = new URLSearchParams(reserved.toString());
+		 assert.equal(decoded.get('名前'), '値 ☃');
+		 assert.equal(decoded.get('safe'), "*-._~!'()+,/:;?@#[]$%");
+		 assert.equal(new URLSearchParams(reserved.toString('rfc3986')).get('a b'), 'x&y=z');`,
+		`try {
+			new URLSearchParams('a=1').toString('url');
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes('unknown format url'), e.toString());
+		 }`,
+		`assert.equal(URLSearchParams.fromURL('https://example.com/').toString(), '')`,
+		`assert.equal(new URLSearchParams('/path?a=1').get('/path?a'), '1')`,
+		`try {
+			URLSearchParams.fromURL('http://[::1');
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes('missing \']\' in host'), e.toString());
+		 }`,
+		`assert.equal(new URLSearchParams('a=https://example.com?b=1').get('a'), 'https://example.com?b=1')`,
 		`params.forEach((v, k) => assert.true(v.length == 1))
 		 assert.equal(params.get('name'), 'foo')`,
 		`params.append('name', 'bar');
@@ -43,6 +83,19 @@ func TestURLSearchParams(t *testing.T) {
 			str += key + "=" + value + ",";
 		 }
 		 assert.equal(str, '000=114,name=foobar,value=zoo,')`,
+		`const query = new URLSearchParams('?b=2&a=x%20y&&b=1&c=%E2%98%83');
+		 assert.equal(query.get('a'), 'x y');
+		 assert.equal(query.get('c'), '☃');
+		 assert.equal(query.getAll('b').join(), '2,1');
+		 query.append('a', 'z');
+		 query.set('d', '&=');
+		 query.delete('c');
+		 assert.equal(query.values().join('|'), '2,1|x y,z|&=');
+		 query.sort();
+		 assert.equal(query.keys().join(), 'a,b,d');
+		 assert.equal(query.toString(), 'a=x+y&a=z&b=2&b=1&d=%26%3D');
+		 assert.equal(new URLSearchParams(query.toString()).toString(), query.toString());
+		 assert.equal(new URLSearchParams('').toString(), '');`,
 	}
 
 	for i, s := range testCase {
@@ -0,0 +1,131 @@
+package http
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/grafana/sobek"
+	"github.com/spf13/cast"
+)
+
+// xmlBody serializes the body as XML and set the content-type, the string
+// and the binary body is sent as-is. The object must have one root element,
+// the attributes are prefixed with "@", the text content is "#text", the array
+// becomes the repeated elements, same as the $json.parse xml fallback output.
+//
+//	{"user": {"@id": "1", "name": "foo", "tag": ["a", "b"]}}
+//	<user id="1"><name>foo</name><tag>a</tag><tag>b</tag></user>
+func xmlBody(body any, headers map[string]string) (io.Reader, error) {
+	var reader io.Reader
+	switch data := body.(type) {
+	case string:
+		reader = strings.NewReader(data)
+	case sobek.ArrayBuffer:
+		reader = bytes.NewReader(data.Bytes())
+	case []byte:
+		reader = bytes.NewReader(data)
+	case map[string]any:
+		if len(data) != 1 {
+			return nil, fmt.Errorf("xml body expected one root element, got %d", len(data))
+		}
+		buf := bytes.NewBufferString(xml.Header)
+		enc := xml.NewEncoder(buf)
+		for name, value := range data {
+			if err := encodeXML(enc, name, value); err != nil {
+				return nil, fmt.Errorf("xml body %w", err)
+			}
+		}
+		if err := enc.Flush(); err != nil {
+			return nil, err
+		}
+		reader = buf
+	default:
+		return nil, fmt.Errorf("unsupported xml body type %T", body)
+	}
+	setContentType(headers, "application/xml")
+	return reader, nil
+}
+
+// encodeXML encodes the element, the object keys are sorted to keep the output stable
+func encodeXML(enc *xml.Encoder, name string, value any) error {
+	if name == "" || strings.HasPrefix(name, "@") || name == "#text" {
+		return fmt.Errorf("invalid element name %q", name)
+	}
+	if values, ok := value.([]any); ok {
+		for _, v := range values {
+			if err := encodeXML(enc, name, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	obj, ok := value.(map[string]any)
+	if !ok {
+		text, err := xmlText(value)
+		if err != nil {
+			return fmt.Errorf("element %s: %w", name, err)
+		}
+		if err = enc.EncodeToken(start); err != nil {
+			return err
+		}
+		if err = enc.EncodeToken(xml.CharData(text)); err != nil {
+			return err
+		}
+		return enc.EncodeToken(start.End())
+	}
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var children []string
+	for _, k := range keys {
+		if attr, ok := strings.CutPrefix(k, "@"); ok {
+			text, err := xmlText(obj[k])
+			if err != nil {
+				return fmt.Errorf("attribute %s: %w", k, err)
+			}
+			start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: attr}, Value: text})
+		} else if k != "#text" {
+			children = append(children, k)
+		}
+	}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if v, ok := obj["#text"]; ok {
+		text, err := xmlText(v)
+		if err != nil {
+			return fmt.Errorf("element %s #text: %w", name, err)
+		}
+		if err = enc.EncodeToken(xml.CharData(text)); err != nil {
+			return err
+		}
+	}
+	for _, k := range children {
+		if err := encodeXML(enc, k, obj[k]); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// xmlText returns the text of the scalar value, the null is empty
+func xmlText(value any) (string, error) {
+	switch value.(type) {
+	case nil:
+		return "", nil
+	case map[string]any, []any:
+		return "", errors.New("expected scalar value")
+	default:
+		return cast.ToStringE(value)
+	}
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXMLBody(t *testing.T) {
+	t.Parallel()
+	vm := createVM(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Content-Type", r.Header.Get("Content-Type"))
+		_, _ = io.Copy(w, r.Body)
+	}))
+	t.Cleanup(ts.Close)
+	_ = vm.Runtime().Set("xmlURL", ts.URL)
+
+	testCase := []string{
+		`const res = http.post(xmlURL, {
+			body: { user: { "@id": 1, name: "foo & bar", tag: ["a", "b"], empty: null, note: { "@lang": "en", "#text": "<hi>" } } },
+			type: "xml",
+		 });
+		 assert.equal(res.headers.get("x-content-type"), "application/xml");
+		 assert.equal(res.text(), '<?xml version="1.0" encoding="UTF-8"?>\n' +
+			'<user id="1"><empty></empty><name>foo &amp; bar</name>' +
+			'<note lang="en">&lt;hi&gt;</note><tag>a</tag><tag>b</tag></user>');`,
+		`const res = http.post(xmlURL, {
+			body: '<soap:Envelope></soap:Envelope>',
+			type: "xml",
+			headers: { "Content-Type": "text/xml; charset=utf-8" },
+		 });
+		 assert.equal(res.headers.get("x-content-type"), "text/xml; charset=utf-8");
+		 assert.equal(res.text(), '<soap:Envelope></soap:Envelope>');`,
+		`try {
+			http.post(xmlURL, { body: { a: 1, b: 2 }, type: "xml" });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("xml body expected one root element, got 2"), e.toString());
+		 }`,
+		`try {
+			http.post(xmlURL, { body: { a: { "@b": [1] } }, type: "xml" });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("xml body attribute @b: expected scalar value"), e.toString());
+		 }`,
+		`try {
+			http.post(xmlURL, { body: 1, type: "xml" });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("unsupported xml body type"), e.toString());
+		 }`,
+		`try {
+			http.post(xmlURL, { body: "a", type: "plist" });
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("options type plist is not supported, must be xml or ndjson"), e.toString());
+		 }`,
+	}
+
+	for i, s := range testCase {
+		t.Run(fmt.Sprintf("Script%v", i), func(t *testing.T) {
+			_, err := vm.Runtime().RunString(fmt.Sprintf(`{%s}`, s))
+			assert.NoError(t, err)
+		})
+	}
+}
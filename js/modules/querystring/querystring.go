@@ -0,0 +1,145 @@
+// Package querystring the querystring JS implementation
+package querystring
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/grafana/sobek"
+	"github.com/shiroyk/ski/js"
+)
+
+func init() {
+	js.Register("querystring", new(QueryString))
+}
+
+// QueryString js module, parsing and formatting URL query strings.
+// Mirroring the https://nodejs.org/api/querystring.html
+type QueryString struct{}
+
+// Instantiate returns module instance
+func (*QueryString) Instantiate(rt *sobek.Runtime) (sobek.Value, error) {
+	return rt.ToValue(map[string]any{
+		"parse":     Parse,
+		"stringify": Stringify,
+		"escape":    Escape,
+		"unescape":  Unescape,
+		"decode":    Parse,
+		"encode":    Stringify,
+	}), nil
+}
+
+// separators returns the sep and eq arguments, default "&" and "="
+func separators(call sobek.FunctionCall) (sep, eq string) {
+	sep, eq = "&", "="
+	if v := call.Argument(1); !sobek.IsUndefined(v) && !sobek.IsNull(v) && v.String() != "" {
+		sep = v.String()
+	}
+	if v := call.Argument(2); !sobek.IsUndefined(v) && !sobek.IsNull(v) && v.String() != "" {
+		eq = v.String()
+	}
+	return
+}
+
+// Parse parses a URL query string into a collection of key and value pairs.
+// querystring.parse(str[, sep[, eq]]), the repeated keys will be an array.
+func Parse(call sobek.FunctionCall, vm *sobek.Runtime) sobek.Value {
+	sep, eq := separators(call)
+	object := vm.NewObject()
+	str := call.Argument(0)
+	if sobek.IsUndefined(str) || sobek.IsNull(str) {
+		return object
+	}
+
+	var keys []string
+	values := make(map[string][]string)
+	for _, kv := range strings.Split(str.String(), sep) {
+		if kv == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(kv, eq)
+		k, v = Unescape(k), Unescape(v)
+		if _, ok := values[k]; !ok {
+			keys = append(keys, k)
+		}
+		values[k] = append(values[k], v)
+	}
+
+	for _, key := range keys {
+		if vs := values[key]; len(vs) == 1 {
+			_ = object.Set(key, vs[0])
+		} else {
+			items := make([]any, len(vs))
+			for i, v := range vs {
+				items[i] = v
+			}
+			_ = object.Set(key, vm.NewArray(items...))
+		}
+	}
+	return object
+}
+
+// Stringify produces a URL query string from a given obj by iterating through the object's "own properties".
+// querystring.stringify(obj[, sep[, eq]]), the array values (include nested arrays) will be the repeated keys.
+func Stringify(call sobek.FunctionCall, vm *sobek.Runtime) sobek.Value {
+	sep, eq := separators(call)
+	obj := call.Argument(0)
+	if sobek.IsUndefined(obj) || sobek.IsNull(obj) {
+		return vm.ToValue("")
+	}
+
+	var buf strings.Builder
+	write := func(key, value string) {
+		if buf.Len() > 0 {
+			buf.WriteString(sep)
+		}
+		buf.WriteString(key)
+		buf.WriteString(eq)
+		buf.WriteString(value)
+	}
+
+	object := obj.ToObject(vm)
+	for _, key := range object.Keys() {
+		ek := Escape(key)
+		var each func(value sobek.Value)
+		each = func(value sobek.Value) {
+			switch {
+			case sobek.IsUndefined(value), sobek.IsNull(value):
+				write(ek, "")
+			default:
+				if arr, ok := value.(*sobek.Object); ok && arr.ClassName() == "Array" {
+					for _, k := range arr.Keys() {
+						each(arr.Get(k))
+					}
+					return
+				}
+				if _, ok := value.(*sobek.Object); ok {
+					write(ek, "")
+					return
+				}
+				write(ek, Escape(value.String()))
+			}
+		}
+		each(object.Get(key))
+	}
+	return vm.ToValue(buf.String())
+}
+
+// escaper restores the characters which are unreserved in the Node.js querystring.escape
+// but encoded by the url.QueryEscape, and encodes the space as %20.
+var escaper = strings.NewReplacer("+", "%20", "%21", "!", "%27", "'", "%28", "(", "%29", ")", "%2A", "*")
+
+// Escape performs URL percent-encoding on the given str as the Node.js querystring.escape,
+// the A-Z a-z 0-9 - _ . ! ~ * ' ( ) are not encoded, the space will be encoded as %20.
+func Escape(str string) string {
+	return escaper.Replace(url.QueryEscape(str))
+}
+
+// Unescape performs decoding of URL percent-encoded characters on the given str,
+// returns the original str if decode failed.
+func Unescape(str string) string {
+	if s, err := url.QueryUnescape(str); err == nil {
+		return s
+	}
+	return str
+}
@@ -0,0 +1,44 @@
+package querystring
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/grafana/sobek"
+	"github.com/shiroyk/ski/js"
+	"github.com/shiroyk/ski/js/modulestest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryString(t *testing.T) {
+	t.Parallel()
+
+	vm := modulestest.New(t, js.WithInitial(func(rt *sobek.Runtime) {
+		instantiate, _ := new(QueryString).Instantiate(rt)
+		_ = rt.Set("querystring", instantiate)
+	}))
+
+	testCases := []string{
+		`assert.equal(querystring.parse("foo=bar&abc=xyz&abc=123"), {foo: "bar", abc: ["xyz", "123"]})`,
+		`assert.equal(querystring.parse("a=1;b=hello+world%21", ";"), {a: "1", b: "hello world!"})`,
+		`assert.equal(querystring.parse("a:1|b:2", "|", ":"), {a: "1", b: "2"})`,
+		`assert.equal(Object.keys(querystring.parse("z=1&a=2&z=3")), ["z", "a"])`,
+		`assert.equal(querystring.parse(""), {})`,
+		`assert.equal(querystring.stringify({foo: "bar", baz: ["qux", "quux"], corge: ""}), "foo=bar&baz=qux&baz=quux&corge=")`,
+		`assert.equal(querystring.stringify({a: [1, [2, 3]], b: null}), "a=1&a=2&a=3&b=")`,
+		`assert.equal(querystring.stringify({foo: "bar", baz: "qux"}, ";", ":"), "foo:bar;baz:qux")`,
+		`assert.equal(querystring.stringify({"k y": "a&b=c", "小": "飼"}), "k%20y=a%26b%3Dc&%E5%B0%8F=%E9%A3%BC")`,
+		`assert.equal(querystring.parse(querystring.stringify({"k y": "a&b=c"})), {"k y": "a&b=c"})`,
+		`assert.equal(querystring.escape("a b"), "a%20b")`,
+		`assert.equal(querystring.escape("!*'()~-_."), "!*'()~-_.")`,
+		`assert.equal(querystring.escape("a+b=c&d/é"), "a%2Bb%3Dc%26d%2F%C3%A9")`,
+		`assert.equal(querystring.unescape("a%20b"), "a b")`,
+	}
+
+	for i, s := range testCases {
+		t.Run(fmt.Sprintf("Script%v", i), func(t *testing.T) {
+			_, err := vm.Runtime().RunString(s)
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Package util the util JS implementation
+package util
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/grafana/sobek"
+	"github.com/shiroyk/ski/js"
+)
+
+func init() {
+	js.Register("util", new(Util))
+}
+
+// Util js module, the array and string helpers for batching
+type Util struct{}
+
+// Instantiate returns module instance
+func (*Util) Instantiate(rt *sobek.Runtime) (sobek.Value, error) {
+	return rt.ToValue(map[string]any{
+		"chunk":   Chunk,
+		"groupBy": GroupBy,
+		"flatten": Flatten,
+	}), nil
+}
+
+// toArray returns the elements of the array-like object
+func toArray(vm *sobek.Runtime, value sobek.Value) []sobek.Value {
+	if sobek.IsUndefined(value) || sobek.IsNull(value) {
+		return nil
+	}
+	object := value.ToObject(vm)
+	length := object.Get("length")
+	if length == nil {
+		js.Throw(vm, errors.New("argument is not an array"))
+	}
+	ret := make([]sobek.Value, length.ToInteger())
+	for i := range ret {
+		ret[i] = object.Get(strconv.Itoa(i))
+	}
+	return ret
+}
+
+func isArray(value sobek.Value) bool {
+	o, ok := value.(*sobek.Object)
+	return ok && o.ClassName() == "Array"
+}
+
+// Chunk creates an array of elements split into groups the length of size,
+// if array can't be split evenly, the final chunk will be the remaining elements.
+// The string will be split into substrings.
+// chunk(array|string, size)
+func Chunk(call sobek.FunctionCall, vm *sobek.Runtime) sobek.Value {
+	size := int(call.Argument(1).ToInteger())
+	if size <= 0 {
+		js.Throw(vm, errors.New("chunk size must be greater than 0"))
+	}
+
+	if str, ok := call.Argument(0).Export().(string); ok {
+		runes := []rune(str)
+		ret := make([]any, 0, (len(runes)+size-1)/size)
+		for i := 0; i < len(runes); i += size {
+			ret = append(ret, string(runes[i:min(i+size, len(runes))]))
+		}
+		return vm.NewArray(ret...)
+	}
+
+	items := toArray(vm, call.Argument(0))
+	ret := make([]any, 0, (len(items)+size-1)/size)
+	for i := 0; i < len(items); i += size {
+		chunk := make([]any, 0, size)
+		for _, item := range items[i:min(i+size, len(items))] {
+			chunk = append(chunk, item)
+		}
+		ret = append(ret, vm.NewArray(chunk...))
+	}
+	return vm.NewArray(ret...)
+}
+
+// GroupBy creates an object composed of keys generated from the results of running
+// each element of array thru key function or property name.
+// groupBy(array, keyFn|property)
+func GroupBy(call sobek.FunctionCall, vm *sobek.Runtime) sobek.Value {
+	items := toArray(vm, call.Argument(0))
+	key := call.Argument(1)
+	keyFn, isFn := sobek.AssertFunction(key)
+	if !isFn && (sobek.IsUndefined(key) || sobek.IsNull(key)) {
+		js.Throw(vm, errors.New("groupBy key must be a function or property name"))
+	}
+
+	var keys []string
+	groups := make(map[string][]any)
+	for i, item := range items {
+		var k sobek.Value
+		if isFn {
+			var err error
+			k, err = keyFn(sobek.Undefined(), item, vm.ToValue(i))
+			if err != nil {
+				js.Throw(vm, err)
+			}
+		} else if o, ok := item.(*sobek.Object); ok {
+			k = o.Get(key.String())
+		}
+		if k == nil {
+			k = sobek.Undefined()
+		}
+		ks := k.String()
+		if _, ok := groups[ks]; !ok {
+			keys = append(keys, ks)
+		}
+		groups[ks] = append(groups[ks], item)
+	}
+
+	object := vm.NewObject()
+	for _, k := range keys {
+		_ = object.Set(k, vm.NewArray(groups[k]...))
+	}
+	return object
+}
+
+// Flatten flattens array up to depth times, default depth is 1.
+// flatten(array[, depth])
+func Flatten(call sobek.FunctionCall, vm *sobek.Runtime) sobek.Value {
+	depth := 1
+	if v := call.Argument(1); !sobek.IsUndefined(v) {
+		if f := v.ToFloat(); math.IsInf(f, 1) {
+			depth = math.MaxInt
+		} else {
+			depth = int(v.ToInteger())
+		}
+	}
+
+	var ret []any
+	var flatten func(items []sobek.Value, depth int)
+	flatten = func(items []sobek.Value, depth int) {
+		for _, item := range items {
+			if depth > 0 && isArray(item) {
+				flatten(toArray(vm, item), depth-1)
+				continue
+			}
+			ret = append(ret, item)
+		}
+	}
+	flatten(toArray(vm, call.Argument(0)), depth)
+	return vm.NewArray(ret...)
+}
@@ -0,0 +1,48 @@
+package util
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/grafana/sobek"
+	"github.com/shiroyk/ski/js"
+	"github.com/shiroyk/ski/js/modulestest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUtil(t *testing.T) {
+	t.Parallel()
+
+	vm := modulestest.New(t, js.WithInitial(func(rt *sobek.Runtime) {
+		instantiate, _ := new(Util).Instantiate(rt)
+		_ = rt.Set("util", instantiate)
+	}))
+
+	testCases := []string{
+		`assert.equal(util.chunk([1, 2, 3, 4, 5], 2), [[1, 2], [3, 4], [5]])`,
+		`assert.equal(util.chunk([1, 2, 3, 4], 2), [[1, 2], [3, 4]])`,
+		`assert.equal(util.chunk([], 2), [])`,
+		`assert.equal(util.chunk("abcde", 2), ["ab", "cd", "e"])`,
+		`assert.equal(util.chunk("小飼弾", 2), ["小飼", "弾"])`,
+		`try {
+			util.chunk([1], 0);
+			assert.true(false);
+		 } catch (e) {
+			assert.true(e.toString().includes("chunk size must be greater than 0"));
+		 }`,
+		`assert.equal(util.groupBy([6.1, 4.2, 6.3], Math.floor), {"6": [6.1, 6.3], "4": [4.2]})`,
+		`assert.equal(util.groupBy([{t: "a", v: 1}, {t: "b", v: 2}, {t: "a", v: 3}], "t"),
+			{a: [{t: "a", v: 1}, {t: "a", v: 3}], b: [{t: "b", v: 2}]})`,
+		`assert.equal(Object.keys(util.groupBy(["one", "two", "three"], (s) => s.length)), ["3", "5"])`,
+		`assert.equal(util.flatten([1, [2, [3, [4]], 5]]), [1, 2, [3, [4]], 5])`,
+		`assert.equal(util.flatten([1, [2, [3, [4]], 5]], Infinity), [1, 2, 3, 4, 5])`,
+		`assert.equal(util.flatten(util.chunk([1, 2, 3], 2)), [1, 2, 3])`,
+	}
+
+	for i, s := range testCases {
+		t.Run(fmt.Sprintf("Script%v", i), func(t *testing.T) {
+			_, err := vm.Runtime().RunString(s)
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package ski
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/spf13/cast"
+)
+
+// _js_var extracts the JSON value assigned to the JavaScript variable from the script content,
+// the surrounding code and the trailing semicolon are ignored.
+//
+//	<script>window.__DATA__ = {"user": {"name": "foo"}};</script>
+//	$js.var: window.__DATA__
+type _js_var struct {
+	name string
+	re   *regexp.Regexp
+}
+
+func new_js_var(args ...Executor) (Executor, error) {
+	if len(args) == 0 {
+		return nil, errors.New("needs the variable name")
+	}
+	name := ExecToString(args[0])
+	if name == "" {
+		return nil, errors.New("needs the variable name")
+	}
+	// match the assignment and exclude the comparison ==
+	re, err := regexp.Compile(`(?:^|[^\w$.])` + regexp.QuoteMeta(name) + `\s*=[^=]`)
+	if err != nil {
+		return nil, err
+	}
+	return _js_var{name, re}, nil
+}
+
+func (v _js_var) Exec(_ context.Context, arg any) (any, error) {
+	if arg == nil {
+		return nil, nil
+	}
+	str, err := cast.ToStringE(arg)
+	if err != nil {
+		return nil, err
+	}
+	for _, loc := range v.re.FindAllStringIndex(str, -1) {
+		// the match ends with the first character after =
+		raw := jsonValue(str[loc[1]-1:])
+		if raw == "" {
+			continue
+		}
+		var ret any
+		if err = json.Unmarshal([]byte(raw), &ret); err != nil {
+			return nil, fmt.Errorf("variable %s: %w", v.name, err)
+		}
+		return ret, nil
+	}
+	return nil, fmt.Errorf("variable %s assignment not found", v.name)
+}
+
+// jsonValue returns the balanced JSON object or array at the start of str, ignore the leading spaces.
+func jsonValue(str string) string {
+	start := -1
+	for i := 0; i < len(str); i++ {
+		switch str[i] {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '{', '[':
+			start = i
+		}
+		break
+	}
+	if start < 0 {
+		return ""
+	}
+
+	var (
+		depth   int
+		quote   byte
+		escaped bool
+	)
+	for i := start; i < len(str); i++ {
+		c := str[i]
+		if quote != 0 {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == quote:
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'':
+			quote = c
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return str[start : i+1]
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,55 @@
+package ski
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSVar(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	const script = `
+	if (window.__DATA__ == null) { console.log("}") }
+	var config = {"debug": false}, other = 1;
+	window.__DATA__ = {
+		"user": {"name": "foo } bar", "tags": ["a", "b]"]},
+		"escaped": "say \"hi\" {"
+	};
+	render(window.__DATA__);`
+
+	testCases := []struct {
+		name string
+		want any
+		err  string
+	}{
+		{"window.__DATA__", map[string]any{
+			"user":    map[string]any{"name": "foo } bar", "tags": []any{"a", "b]"}},
+			"escaped": `say "hi" {`,
+		}, ""},
+		{"config", map[string]any{"debug": false}, ""},
+		{"other", nil, "variable other assignment not found"},
+		{"__DATA__", nil, "variable __DATA__ assignment not found"},
+		{"missing", nil, "variable missing assignment not found"},
+	}
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			exec, err := new_js_var(String(c.name))
+			if !assert.NoError(t, err) {
+				return
+			}
+			v, err := exec.Exec(ctx, script)
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.want, v)
+			}
+		})
+	}
+
+	_, err := new_js_var(String(""))
+	assert.ErrorContains(t, err, "needs the variable name")
+}
@@ -0,0 +1,133 @@
+package ski
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Merge deep merges the override schemas into the base schema, the override wins.
+// The properties are merged recursively, the executors are merged when both sides
+// have the same executors, otherwise the override replaces the base.
+// A null override value removes the property from the base.
+//
+//	base:
+//	  $map:
+//	    title: { $css: h1 }
+//	    author: { $css: .author }
+//	override:
+//	  $map:
+//	    title: { $css: h2 }
+//	    date: { $css: .date }
+//	    author: ~
+func Merge(base string, overrides ...string) (string, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(base), &root); err != nil {
+		return "", syntaxError(base, err)
+	}
+	merged := expandAlias(documentContent(&root))
+	for _, override := range overrides {
+		var node yaml.Node
+		if err := yaml.Unmarshal([]byte(override), &node); err != nil {
+			return "", syntaxError(override, err)
+		}
+		merged = mergeNode(merged, expandAlias(documentContent(&node)))
+	}
+	if merged == nil {
+		return "", nil
+	}
+	out, err := yaml.Marshal(merged)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// WithOverride merges the override schemas into the compiled schema, see Merge.
+func WithOverride(overrides ...string) Option {
+	return func(c *compiler) { c.overrides = append(c.overrides, overrides...) }
+}
+
+func documentContent(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return node.Content[0]
+	}
+	return node
+}
+
+// expandAlias returns a copy of the node with the aliases replaced by
+// the anchored nodes, so the merged result has no dangling aliases.
+func expandAlias(node *yaml.Node) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	expanded := *node
+	expanded.Anchor = ""
+	if len(node.Content) > 0 {
+		expanded.Content = make([]*yaml.Node, len(node.Content))
+		for i, child := range node.Content {
+			expanded.Content[i] = expandAlias(child)
+		}
+	}
+	return &expanded
+}
+
+func mergeNode(base, override *yaml.Node) *yaml.Node {
+	if override == nil {
+		return base
+	}
+	if base == nil || base.Kind != yaml.MappingNode || override.Kind != yaml.MappingNode ||
+		isExecutorMapping(base) != isExecutorMapping(override) ||
+		(isExecutorMapping(base) && !sameKeys(base, override)) {
+		return override
+	}
+
+	merged := *base
+	merged.Content = append([]*yaml.Node(nil), base.Content...)
+	for i := 0; i < len(override.Content); i += 2 {
+		key, value := override.Content[i], override.Content[i+1]
+		idx := mappingIndex(&merged, key.Value)
+		switch {
+		case value.Tag == "!!null":
+			if idx >= 0 {
+				merged.Content = append(merged.Content[:idx], merged.Content[idx+2:]...)
+			}
+		case idx >= 0:
+			merged.Content[idx+1] = mergeNode(merged.Content[idx+1], value)
+		default:
+			merged.Content = append(merged.Content, key, value)
+		}
+	}
+	return &merged
+}
+
+func isExecutorMapping(node *yaml.Node) bool {
+	return len(node.Content) > 0 && strings.HasPrefix(node.Content[0].Value, "$")
+}
+
+func sameKeys(a, b *yaml.Node) bool {
+	if len(a.Content) != len(b.Content) {
+		return false
+	}
+	for i := 0; i < len(a.Content); i += 2 {
+		if a.Content[i].Value != b.Content[i].Value {
+			return false
+		}
+	}
+	return true
+}
+
+func mappingIndex(node *yaml.Node, key string) int {
+	for i := 0; i < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,70 @@
+package ski
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+	base := `
+$map:
+  title: &title
+    $kind: string
+  meta:
+    $map:
+      author:
+        $string.strip_prefix: "by "
+      tags: *title
+  views:
+    $kind: int`
+	override := `
+$map:
+  meta:
+    $map:
+      author:
+        $string.strip_prefix: "author: "
+      lang: en
+  views:
+    $kind: float64
+    $debug: views
+  title: ~`
+
+	merged, err := Merge(base, override)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, `$map:
+    meta:
+        $map:
+            author:
+                $string.strip_prefix: "author: "
+            tags:
+                $kind: string
+            lang: en
+    views:
+        $kind: float64
+        $debug: views
+`, merged)
+
+	exec, err := Compile(base, WithOverride(override))
+	if !assert.NoError(t, err) {
+		return
+	}
+	v, err := exec.Exec(context.Background(), "author: foo")
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]any{
+			"meta": map[string]any{
+				"author": "foo",
+				"tags":   "author: foo",
+				"lang":   "en",
+			},
+			"views": nil,
+		}, v)
+	}
+
+	_, err = Merge(base, "$map: [")
+	assert.Error(t, err)
+}
@@ -0,0 +1,243 @@
+package ski
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/spf13/cast"
+)
+
+// _number parse the localized number string into the float64, the currency symbols
+// and units around the number are ignored, the Iterator is parsed element-wise.
+// The minus sign is kept only if it is next to the number, see negative.
+// The group separators must split the integer part into groups of three digits.
+//
+//	$number: de # the locale, default is en
+//	$number:
+//	  decimal: "," # the decimal separator
+//	  group: " "   # the group separator, space includes the no-break spaces
+type _number struct{ decimal, group string }
+
+// numberLocales the decimal and group separators of the locale languages
+var numberLocales = map[string]_number{
+	"en": {".", ","},
+	"zh": {".", ","},
+	"ja": {".", ","},
+	"ko": {".", ","},
+	"de": {",", "."},
+	"es": {",", "."},
+	"it": {",", "."},
+	"nl": {",", "."},
+	"pt": {",", "."},
+	"id": {",", "."},
+	"tr": {",", "."},
+	"fr": {",", " "},
+	"ru": {",", " "},
+	"pl": {",", " "},
+	"cs": {",", " "},
+	"sv": {",", " "},
+	"fi": {",", " "},
+	"nb": {",", " "},
+	"uk": {",", " "},
+	"ch": {".", "'"},
+}
+
+// numberLocale returns the separators of the locale tag. eg: "en-US", "de_DE", "de-CH"
+func numberLocale(tag string) (_number, bool) {
+	lang, region, _ := strings.Cut(strings.ReplaceAll(strings.ToLower(tag), "_", "-"), "-")
+	if region == "ch" || region == "li" {
+		return numberLocales["ch"], true
+	}
+	n, ok := numberLocales[lang]
+	return n, ok
+}
+
+func new_number(args ...Executor) (Executor, error) {
+	n := numberLocales["en"]
+	if len(args) == 1 {
+		if tag := ExecToString(args[0]); tag != "" {
+			var ok bool
+			if n, ok = numberLocale(tag); !ok {
+				return nil, fmt.Errorf("unknown number locale %s", tag)
+			}
+		}
+		return n, nil
+	}
+	if len(args)%2 != 0 {
+		return nil, fmt.Errorf("unexpected arguments, expected locale, decimal or group mapping")
+	}
+	for i := 0; i < len(args); i += 2 {
+		value := ExecToString(args[i+1])
+		switch key := ExecToString(args[i]); key {
+		case "locale":
+			var ok bool
+			if n, ok = numberLocale(value); !ok {
+				return nil, fmt.Errorf("unknown number locale %s", value)
+			}
+		case "decimal":
+			n.decimal = value
+		case "group":
+			n.group = value
+		default:
+			return nil, fmt.Errorf("unknown number option %s", key)
+		}
+	}
+	if n.decimal == "" || n.decimal == n.group {
+		return nil, fmt.Errorf("invalid number decimal separator %q", n.decimal)
+	}
+	return n, nil
+}
+
+func (n _number) Exec(ctx context.Context, arg any) (any, error) {
+	switch v := arg.(type) {
+	case nil:
+		return nil, nil
+	case Iterator:
+		ret := make([]any, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			f, err := n.Exec(ctx, v.At(i))
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, f)
+		}
+		return NewIterator(ret), nil
+	case string:
+		return n.parse(v)
+	default:
+		return cast.ToFloat64E(arg)
+	}
+}
+
+func (n _number) parse(str string) (float64, error) {
+	s, neg, ok := stripNumber(str, n.decimal)
+	if !ok {
+		return 0, fmt.Errorf("cannot parse number %q with decimal %q and group %q", str, n.decimal, n.group)
+	}
+	if n.group == " " {
+		s = strings.NewReplacer("\u00a0", " ", "\u202f", " ").Replace(s)
+	}
+
+	integer, fraction, _ := strings.Cut(s, n.decimal)
+	if n.group != "" && strings.Contains(integer, n.group) {
+		groups := strings.Split(integer, n.group)
+		for i, g := range groups {
+			if (i == 0 && (len(g) == 0 || len(g) > 3)) || (i > 0 && len(g) != 3) {
+				return 0, fmt.Errorf("cannot parse number %q with decimal %q and group %q", str, n.decimal, n.group)
+			}
+		}
+		integer = strings.Join(groups, "")
+	}
+	if fraction != "" {
+		integer += "." + fraction
+	}
+	if neg {
+		integer = "-" + integer
+	}
+	f, err := strconv.ParseFloat(integer, 64)
+	if err != nil {
+		return 0, fmt.Errorf("cannot parse number %q with decimal %q and group %q", str, n.decimal, n.group)
+	}
+	return f, nil
+}
+
+// _strip_kind strips the currency symbols and units around the number before the
+// number kind conversion, the sign before the number is kept and the group separators
+// are removed, default ",". It is opt-in by the strip argument of the $kind.
+//
+//	$kind: [ int64, strip ]    # "$1,299" => 1299, "-42 items" => -42
+//	$kind: [ int, strip, "." ] # "1.299 €" => 1299
+type _strip_kind struct {
+	kind  Kind
+	group string
+}
+
+func new_strip_kind(k Kind, args ...Executor) (Executor, error) {
+	if mode := ExecToString(args[0]); mode != "strip" {
+		return nil, fmt.Errorf("unknown kind option %s, must be strip", mode)
+	}
+	switch k {
+	case KindInt, KindInt64, KindFloat, KindFloat64:
+	default:
+		return nil, fmt.Errorf("kind %s cannot strip, must be number kind", k)
+	}
+	group := ","
+	if len(args) > 1 {
+		group = ExecToString(args[1])
+	}
+	return _strip_kind{k, group}, nil
+}
+
+func (k _strip_kind) Exec(ctx context.Context, arg any) (any, error) {
+	str, ok := arg.(string)
+	if !ok {
+		return k.kind.Exec(ctx, arg)
+	}
+	// the decimal separator is kept for the int kinds as well, so "$.99" is not 99
+	decimal := "."
+	if k.group == "." {
+		decimal = ""
+	}
+	s, neg, ok := stripNumber(str, decimal)
+	if !ok {
+		return nil, fmt.Errorf("cannot strip number %q", str)
+	}
+	if k.group != "" {
+		s = strings.ReplaceAll(s, k.group, "")
+	}
+	if neg {
+		s = "-" + s
+	}
+	v, err := k.kind.Exec(ctx, s)
+	if err != nil {
+		return nil, fmt.Errorf("cannot strip number %q: %w", str, err)
+	}
+	return v, nil
+}
+
+// stripNumber returns the number between the first and the last digit of the string, the
+// decimal separator right before the first digit is kept, eg: "$.99". The leading zeros
+// are trimmed since they are not the octal prefix, eg: "00123". neg reports whether
+// the number has the minus sign, see negative.
+func stripNumber(str, decimal string) (s string, neg, ok bool) {
+	start := strings.IndexFunc(str, unicode.IsDigit)
+	if start < 0 {
+		return "", false, false
+	}
+	end := strings.LastIndexFunc(str, unicode.IsDigit) + 1
+	if decimal != "" && strings.HasSuffix(str[:start], decimal) {
+		before, _ := utf8.DecodeLastRuneInString(str[:start-len(decimal)])
+		if !unicode.IsLetter(before) {
+			start -= len(decimal)
+		}
+	}
+	s = str[start:end]
+	for len(s) > 1 && s[0] == '0' && s[1] >= '0' && s[1] <= '9' {
+		s = s[1:]
+	}
+	return s, negative(str[:start]), true
+}
+
+// negative reports whether the prefix ends with the minus sign of the number, the sign
+// is separated from the number only by the currency symbols or spaces, and it is not
+// the hyphen after a word, eg: "SKU-00123".
+func negative(prefix string) bool {
+	i := strings.LastIndexFunc(prefix, func(r rune) bool {
+		return !unicode.IsSpace(r) && !unicode.Is(unicode.Sc, r)
+	})
+	if i < 0 {
+		return false
+	}
+	if r, _ := utf8.DecodeRuneInString(prefix[i:]); r != '-' && r != '\u2212' {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	before, _ := utf8.DecodeLastRuneInString(prefix[:i])
+	return !unicode.IsLetter(before) && !unicode.IsDigit(before)
+}
@@ -0,0 +1,117 @@
+package ski
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumber(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testCases := []struct {
+		schema string
+		arg    any
+		want   any
+		err    string
+	}{
+		{`$number:`, "1,234.56", 1234.56, ""},
+		{`$number: en-US`, "$1,234,567.5", 1234567.5, ""},
+		{`$number:`, "-42", float64(-42), ""},
+		{`$number:`, "1234", float64(1234), ""},
+		{`$number:`, "SKU-00123", float64(123), ""},
+		{`$number:`, "Balance: -$1,299.5", -1299.5, ""},
+		{`$number:`, "$.99", 0.99, ""},
+		{`$number:`, "-.5 pts", -0.5, ""},
+		{`$number:`, "No.5", float64(5), ""},
+		{`$number: de`, "€ ,99", 0.99, ""},
+		{`$number:`, 3, float64(3), ""},
+		{`$number:`, nil, nil, ""},
+		{`$number: de`, "1.234,56 €", 1234.56, ""},
+		{`$number: { locale: it_IT }`, "EUR 12.345", float64(12345), ""},
+		{`$number: fr`, "1\u00a0234,56\u00a0€", 1234.56, ""},
+		{`$number: fr`, "1\u202f234\u202f567,8", 1234567.8, ""},
+		{`$number: de-CH`, "CHF 1'234.50", 1234.5, ""},
+		{`$number: { decimal: ",", group: " " }`, "12 345,6", 12345.6, ""},
+		{`$number: { decimal: "." }`, "1234.5", 1234.5, ""},
+		{`$number:`, NewIterator([]string{"1,000", "2.5"}), NewIterator([]any{float64(1000), 2.5}), ""},
+		{`$number:`, "1.234,56", nil, `cannot parse number "1.234,56" with decimal "." and group ","`},
+		{`$number:`, "1,5", nil, `cannot parse number "1,5"`},
+		{`$number: de`, "1,234.56", nil, `cannot parse number "1,234.56" with decimal "," and group "."`},
+		{`$number:`, "free", nil, `cannot parse number "free"`},
+	}
+	for _, c := range testCases {
+		t.Run(c.schema, func(t *testing.T) {
+			exec, err := Compile(c.schema)
+			if !assert.NoError(t, err) {
+				return
+			}
+			v, err := exec.Exec(ctx, c.arg)
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.want, v)
+			}
+		})
+	}
+	_, err := Compile(`$number: xx`)
+	assert.ErrorContains(t, err, "unknown number locale xx")
+	_, err = Compile(`$number: { decimal: ",", group: "," }`)
+	assert.ErrorContains(t, err, `invalid number decimal separator ","`)
+}
+
+func TestStripKind(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testCases := []struct {
+		schema string
+		arg    any
+		want   any
+		err    string
+	}{
+		{`$kind: [ int64, strip ]`, "$1,299", int64(1299), ""},
+		{`$kind: [ int64, strip ]`, "42 items", int64(42), ""},
+		{`$kind: [ int, strip ]`, "Total: 1,234,567 views", int32(1234567), ""},
+		{`$kind: [ int64, strip ]`, "-$1,299", int64(-1299), ""},
+		{`$kind: [ int64, strip ]`, "$-12 USD", int64(-12), ""},
+		{`$kind: [ int64, strip ]`, "−15°C", int64(-15), ""},
+		{`$kind: [ int64, strip ]`, "Balance: - $1,299", int64(-1299), ""},
+		{`$kind: [ int64, strip ]`, "(-42)", int64(-42), ""},
+		{`$kind: [ int64, strip ]`, "SKU-00123", int64(123), ""},
+		{`$kind: [ int64, strip ]`, "Model X-100", int64(100), ""},
+		{`$kind: [ float64, strip ]`, "007.50 pts", 7.5, ""},
+		{`$kind: [ float64, strip ]`, "$.99", 0.99, ""},
+		{`$kind: [ float64, strip ]`, "-.5 pts", -0.5, ""},
+		{`$kind: [ float64, strip ]`, "SKU-00123", float64(123), ""},
+		{`$kind: [ int64, strip ]`, "$.99", nil, `cannot strip number "$.99"`},
+		{`$kind: [ int, strip, "." ]`, "1.299 €", int32(1299), ""},
+		{`$kind: [ float64, strip ]`, "Price: $5.5 USD", 5.5, ""},
+		{`$kind: [ int64, strip ]`, 12, int64(12), ""},
+		{`$kind: [ int64, strip ]`, "free", nil, `cannot strip number "free"`},
+		{`$kind: [ int, strip ]`, "4.5 stars", nil, `cannot strip number "4.5 stars"`},
+		{`$kind: int64`, "$1,299", nil, `unable to cast "$1,299"`},
+	}
+	for _, c := range testCases {
+		t.Run(c.schema, func(t *testing.T) {
+			exec, err := Compile(c.schema)
+			if !assert.NoError(t, err) {
+				return
+			}
+			v, err := exec.Exec(ctx, c.arg)
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.want, v)
+			}
+		})
+	}
+	_, err := Compile(`$kind: [ string, strip ]`)
+	assert.ErrorContains(t, err, "kind string cannot strip")
+	_, err = Compile(`$kind: [ int, trim ]`)
+	assert.ErrorContains(t, err, "unknown kind option trim")
+}
@@ -0,0 +1,132 @@
+package ski
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/spf13/cast"
+)
+
+// defaultMaxPages the default maximum number of the pages followed by the $paginate
+const defaultMaxPages = 10
+
+// _paginate fetches the pages from the start URL with the Fetch on context and
+// aggregates the items of each page, see WithFetch. The first rule extracts the next page
+// URL from the page content, relative to the page URL. The second rule extracts the items,
+// the Iterator and the array are flattened. The optional third is the maximum number of pages, default 10.
+//
+//	$paginate:
+//	  - $css: a.next
+//	    $attr: href
+//	  - $css: .item
+//	    $each:
+//	      $text:
+//	  - 5
+//
+// The pagination stops if the next URL is empty, the URL has been fetched or the limit is reached.
+type _paginate struct {
+	next, items Executor
+	max         int
+}
+
+func new_paginate(args ...Executor) (Executor, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, errors.New("paginate needs 2 or 3 parameters")
+	}
+	p := _paginate{next: args[0], items: args[1], max: defaultMaxPages}
+	if len(args) == 3 {
+		n, err := strconv.Atoi(ExecToString(args[2]))
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("paginate invalid max pages %s", ExecToString(args[2]))
+		}
+		p.max = n
+	}
+	return p, nil
+}
+
+func (p _paginate) Exec(ctx context.Context, arg any) (any, error) {
+	fetch := FetchFromContext(ctx)
+	if fetch == nil {
+		return nil, errors.New("paginate fetch not found on context")
+	}
+	start, err := cast.ToStringE(arg)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(start)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]any, 0)
+	visited := make(map[string]struct{}, p.max)
+	for i := 0; i < p.max && u != nil; i++ {
+		if _, ok := visited[u.String()]; ok {
+			break
+		}
+		visited[u.String()] = struct{}{}
+		if err = CheckDeadlineBudget(ctx); err != nil {
+			return NewIterator(ret), err
+		}
+
+		content, base, err := p.fetch(ctx, fetch, u)
+		if err != nil {
+			return NewIterator(ret), err
+		}
+		items, err := p.items.Exec(ctx, content)
+		if err != nil {
+			return NewIterator(ret), fmt.Errorf("paginate %s items: %w", u, err)
+		}
+		switch s := items.(type) {
+		case nil:
+		case Iterator:
+			for j := 0; j < s.Len(); j++ {
+				ret = append(ret, s.At(j))
+			}
+		case []any:
+			ret = append(ret, s...)
+		default:
+			ret = append(ret, s)
+		}
+
+		next, err := p.next.Exec(ctx, content)
+		if err != nil {
+			return NewIterator(ret), fmt.Errorf("paginate %s next: %w", u, err)
+		}
+		u = nil
+		if target := cast.ToString(next); target != "" {
+			if u, err = base.Parse(target); err != nil {
+				return NewIterator(ret), fmt.Errorf("paginate %s next: %w", base, err)
+			}
+		}
+	}
+	return NewIterator(ret), nil
+}
+
+// fetch returns the page content and the final URL after the redirects
+func (p _paginate) fetch(ctx context.Context, fetch Fetch, u *url.URL) (string, *url.URL, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", nil, err
+	}
+	res, err := fetch.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("paginate %s: %w", u, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return "", nil, fmt.Errorf("paginate %s: unexpected status %s", u, res.Status)
+	}
+	body, err := ReadBody(res.Body)
+	if err != nil {
+		return "", nil, fmt.Errorf("paginate %s: %w", u, err)
+	}
+	if res.Request != nil && res.Request.URL != nil {
+		u = res.Request.URL
+	}
+	return string(body), u, nil
+}
@@ -0,0 +1,102 @@
+package ski
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginate(t *testing.T) {
+	t.Parallel()
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/page/1":
+			_, _ = fmt.Fprint(w, `var items = ["a", "b"]; next=/page/2`)
+		case "/page/2":
+			_, _ = fmt.Fprint(w, `var items = ["c"]; next=3`)
+		case "/page/3":
+			_, _ = fmt.Fprint(w, `var items = ["d", "e"]; next=`)
+		case "/loop":
+			_, _ = fmt.Fprint(w, `var items = ["f"]; next=/loop?page=1`)
+		case "/small":
+			_, _ = fmt.Fprint(w, `var items = ["g"]; next=/large`)
+		case "/large":
+			_, _ = fmt.Fprintf(w, `var items = ["h"]; next=%s`, strings.Repeat("x", 1024))
+		case "/redirect":
+			http.Redirect(w, r, "/page/2", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	ctx := WithFetch(context.Background(), NewFetch())
+
+	const schema = `
+$paginate:
+  - $string.replace: [ '(?s)^.*next=(\S*).*$', '$1' ]
+  - $js.var: items
+%s`
+	testCases := []struct {
+		path, max string
+		want      []any
+		requests  int32
+		err       string
+	}{
+		{"/page/1", "", []any{"a", "b", "c", "d", "e"}, 3, ""},
+		{"/page/1", "  - 2", []any{"a", "b", "c"}, 2, ""},
+		{"/redirect", "", []any{"c", "d", "e"}, 3, ""},
+		{"/loop", "", []any{"f", "f"}, 2, ""},
+		{"/missing", "", []any{}, 1, "unexpected status 404 Not Found"},
+	}
+	for _, c := range testCases {
+		t.Run(c.path, func(t *testing.T) {
+			exec, err := Compile(fmt.Sprintf(schema, c.max))
+			if !assert.NoError(t, err) {
+				return
+			}
+			requests.Store(0)
+			v, err := exec.Exec(ctx, ts.URL+c.path)
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, NewIterator(c.want), v)
+			assert.Equal(t, c.requests, requests.Load())
+		})
+	}
+
+	t.Run("body too large", func(t *testing.T) {
+		exec, err := Compile(fmt.Sprintf(schema, ""))
+		if !assert.NoError(t, err) {
+			return
+		}
+		ctx := WithFetch(context.Background(), NewFetcher(FetchOptions{MaxBodySize: 64}))
+		v, err := exec.Exec(ctx, ts.URL+"/small")
+		assert.ErrorIs(t, err, ErrBodyTooLarge)
+		assert.ErrorContains(t, err, ts.URL+"/large")
+		assert.Equal(t, NewIterator([]any{"g"}), v)
+	})
+
+	t.Run("no fetch", func(t *testing.T) {
+		exec, err := new_paginate(String(""), String(""))
+		if !assert.NoError(t, err) {
+			return
+		}
+		_, err = exec.Exec(context.Background(), ts.URL)
+		assert.ErrorContains(t, err, "fetch not found")
+	})
+
+	t.Run("invalid max", func(t *testing.T) {
+		_, err := new_paginate(String(""), String(""), String("0"))
+		assert.ErrorContains(t, err, "invalid max pages")
+	})
+}
@@ -0,0 +1,123 @@
+package ski
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetcherProxyRotator(t *testing.T) {
+	t.Parallel()
+	proxies := make([]string, 3)
+	for i, name := range []string{"a", "b", "c"} {
+		name := name
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = io.WriteString(w, name)
+		}))
+		t.Cleanup(ts.Close)
+		proxies[i] = ts.URL
+	}
+	override := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "override")
+	}))
+	t.Cleanup(override.Close)
+
+	get := func(fetch *Fetcher, ctx context.Context) (string, *url.URL) {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com", nil)
+		res, err := fetch.Do(req)
+		if !assert.NoError(t, err) {
+			return "", nil
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		return string(body), ProxyFromResponse(res)
+	}
+
+	fetch := NewFetcher(FetchOptions{ProxyRotator: ProxyRotator{Proxies: proxies}})
+	for i := 0; i < 6; i++ {
+		body, proxy := get(fetch, context.Background())
+		assert.Equal(t, []string{"a", "b", "c"}[i%3], body)
+		assert.Equal(t, proxies[i%3], proxy.String())
+	}
+
+	overrideURL, _ := url.Parse(override.URL)
+	body, proxy := get(fetch, WithProxyURL(context.Background(), overrideURL))
+	assert.Equal(t, "override", body)
+	assert.Equal(t, overrideURL, proxy)
+	// the override does not advance the rotation
+	body, _ = get(fetch, context.Background())
+	assert.Equal(t, "a", body)
+
+	random := NewFetcher(FetchOptions{ProxyRotator: ProxyRotator{Proxies: proxies, Strategy: "random"}})
+	for i := 0; i < 5; i++ {
+		body, proxy = get(random, context.Background())
+		assert.Contains(t, []string{"a", "b", "c"}, body)
+		assert.Contains(t, proxies, proxy.String())
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	_, err := NewFetcher(FetchOptions{ProxyRotator: ProxyRotator{Proxies: proxies, Strategy: "weighted"}}).Do(req)
+	assert.ErrorContains(t, err, "unknown proxy rotation strategy weighted")
+	_, err = NewFetcher(FetchOptions{ProxyRotator: ProxyRotator{Proxies: []string{"http://[::1"}}}).Do(req)
+	assert.ErrorContains(t, err, "invalid proxy URL")
+
+	assert.Nil(t, ProxyFromResponse(nil))
+}
+
+func TestFetcherUserAgents(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Header.Get("User-Agent"))
+	}))
+	t.Cleanup(ts.Close)
+
+	agents := []string{"agent/1", "agent/2", "agent/3"}
+	get := func(fetch *Fetcher, agent string) (string, string) {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+		if agent != "" {
+			req.Header.Set("User-Agent", agent)
+		}
+		res, err := fetch.Do(req)
+		if !assert.NoError(t, err) {
+			return "", ""
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		return string(body), UserAgentFromResponse(res)
+	}
+
+	fetch := NewFetcher(FetchOptions{
+		UserAgents: agents,
+		Headers:    map[string]string{"User-Agent": "default"},
+	})
+	for i := 0; i < 6; i++ {
+		body, agent := get(fetch, "")
+		assert.Equal(t, agents[i%3], body)
+		assert.Equal(t, agents[i%3], agent)
+	}
+
+	body, agent := get(fetch, "custom")
+	assert.Equal(t, "custom", body)
+	assert.Equal(t, "custom", agent)
+	// the override does not advance the rotation
+	body, _ = get(fetch, "")
+	assert.Equal(t, "agent/1", body)
+
+	random := NewFetcher(FetchOptions{UserAgents: agents, UserAgentStrategy: "random"})
+	for i := 0; i < 5; i++ {
+		body, agent = get(random, "")
+		assert.Contains(t, agents, body)
+		assert.Equal(t, body, agent)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+	_, err := NewFetcher(FetchOptions{UserAgents: agents, UserAgentStrategy: "weighted"}).Do(req)
+	assert.ErrorContains(t, err, "unknown user agent rotation strategy weighted")
+
+	assert.Empty(t, UserAgentFromResponse(nil))
+}
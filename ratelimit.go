@@ -0,0 +1,94 @@
+package ski
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimit the token bucket rate limit of the requests, the request waits for
+// the token instead of failing, the waiting is canceled with the request context.
+type RateLimit struct {
+	// RPS the global requests per second, zero means unlimited.
+	RPS float64 `yaml:"rps" json:"rps"`
+	// Burst the maximum number of requests at once, default is 1.
+	Burst int `yaml:"burst" json:"burst"`
+	// Hosts the requests per second of the host, overrides the global RPS.
+	// The key is the host with or without port, eg: "example.com", "example.com:8080".
+	Hosts map[string]float64 `yaml:"hosts" json:"hosts"`
+}
+
+// limiter the global and per-host token buckets
+type limiter struct {
+	global *bucket
+	hosts  map[string]*bucket
+}
+
+func newLimiter(opt RateLimit) *limiter {
+	burst := float64(max(opt.Burst, 1))
+	l := &limiter{hosts: make(map[string]*bucket, len(opt.Hosts))}
+	if opt.RPS > 0 {
+		l.global = newBucket(opt.RPS, burst)
+	}
+	for host, rps := range opt.Hosts {
+		if rps > 0 {
+			l.hosts[host] = newBucket(rps, burst)
+		}
+	}
+	if l.global == nil && len(l.hosts) == 0 {
+		return nil
+	}
+	return l
+}
+
+// wait blocks until the request to the host is allowed or the context is done
+func (l *limiter) wait(ctx context.Context, host, hostname string) error {
+	b, ok := l.hosts[host]
+	if !ok {
+		if b, ok = l.hosts[hostname]; !ok {
+			b = l.global
+		}
+	}
+	if b == nil {
+		return nil
+	}
+	return b.wait(ctx)
+}
+
+type bucket struct {
+	mu          sync.Mutex
+	rate, burst float64
+	tokens      float64
+	last        time.Time
+}
+
+func newBucket(rate, burst float64) *bucket {
+	return &bucket{rate: rate, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// wait reserves a token and waits until it is available,
+// the token is returned if the context is done before.
+func (b *bucket) wait(ctx context.Context) error {
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens--
+	delay := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	b.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return context.Cause(ctx)
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,78 @@
+package ski
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetcherRateLimit(t *testing.T) {
+	t.Parallel()
+	handler := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	slow := httptest.NewServer(handler)
+	defer slow.Close()
+	fast := httptest.NewServer(handler)
+	defer fast.Close()
+	fastURL, _ := url.Parse(fast.URL)
+
+	fetch := NewFetcher(FetchOptions{RateLimit: RateLimit{
+		RPS:   20,
+		Hosts: map[string]float64{fastURL.Host: 1000},
+	}})
+
+	// run the concurrent requests, returns the elapsed time
+	run := func(target string, n int) time.Duration {
+		start := time.Now()
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				req, _ := http.NewRequest(http.MethodGet, target, nil)
+				res, err := fetch.Do(req)
+				if assert.NoError(t, err) {
+					_ = res.Body.Close()
+				}
+			}()
+		}
+		wg.Wait()
+		return time.Since(start)
+	}
+
+	// the first request takes the initial token, the rest wait 50ms each
+	elapsed := run(slow.URL, 11)
+	assert.GreaterOrEqual(t, elapsed, 500*time.Millisecond)
+	assert.Less(t, elapsed, 1500*time.Millisecond)
+
+	// the per-host override is not limited by the global rate
+	assert.Less(t, run(fast.URL, 11), 200*time.Millisecond)
+
+	// the waiting is canceled by the context
+	_ = run(slow.URL, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, slow.URL, nil)
+	_, err := fetch.Do(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.Nil(t, newLimiter(RateLimit{}))
+}
+
+func TestBucket(t *testing.T) {
+	t.Parallel()
+	b := newBucket(100, 3)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, b.wait(context.Background()))
+	}
+	// the burst 3 is immediate, the rest 2 wait 10ms each
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, elapsed, 20*time.Millisecond)
+	assert.Less(t, elapsed, 200*time.Millisecond)
+}
@@ -0,0 +1,213 @@
+package ski
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// metaRefreshPeekSize the size of HTML to detect the meta refresh
+const metaRefreshPeekSize = 32 << 10
+
+var jsRedirectRegexp = regexp.MustCompile(
+	`(?:window\.|document\.|self\.|top\.)?location(?:\.href)?\s*=\s*["']([^"']+)["']|` +
+		`location\.(?:replace|assign)\(\s*["']([^"']+)["']\s*\)`)
+
+// metaRefresh detects the target URL of the HTML meta refresh or JS redirect,
+// returns nil if not found. The read content will be put back to the response body.
+func metaRefresh(res *http.Response) *url.URL {
+	if res.StatusCode != http.StatusOK {
+		return nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil
+	}
+
+	peek, err := io.ReadAll(io.LimitReader(res.Body, metaRefreshPeekSize))
+	res.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(peek), res.Body), res.Body}
+	if err != nil {
+		return nil
+	}
+
+	target := refreshTarget(peek)
+	if target == "" {
+		return nil
+	}
+	base := res.Request.URL
+	u, err := base.Parse(target)
+	if err != nil || *u == *base {
+		return nil
+	}
+	return u
+}
+
+// refreshTarget returns the first meta refresh or JS redirect target in the HTML
+func refreshTarget(data []byte) string {
+	z := html.NewTokenizer(bytes.NewReader(data))
+	inScript := false
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			switch atom.Lookup(name) {
+			case atom.Meta:
+				var equiv, content string
+				for hasAttr {
+					var k, v []byte
+					k, v, hasAttr = z.TagAttr()
+					switch string(k) {
+					case "http-equiv":
+						equiv = string(v)
+					case "content":
+						content = string(v)
+					}
+				}
+				if strings.EqualFold(equiv, "refresh") {
+					if target := refreshURL(content); target != "" {
+						return target
+					}
+				}
+			case atom.Script:
+				inScript = true
+			case atom.Body:
+				return ""
+			}
+		case html.EndTagToken:
+			if name, _ := z.TagName(); atom.Lookup(name) == atom.Script {
+				inScript = false
+			}
+		case html.TextToken:
+			if !inScript {
+				continue
+			}
+			if m := jsRedirectRegexp.FindSubmatch(z.Text()); m != nil {
+				if len(m[1]) > 0 {
+					return string(m[1])
+				}
+				return string(m[2])
+			}
+		}
+	}
+}
+
+// refreshURL returns the URL of the meta refresh content. eg: `0; url=https://example.com`
+func refreshURL(content string) string {
+	_, target, ok := strings.Cut(content, ";")
+	if !ok {
+		_, target, ok = strings.Cut(content, ",")
+		if !ok {
+			return ""
+		}
+	}
+	target = strings.TrimSpace(target)
+	if len(target) > 4 && strings.EqualFold(target[:4], "url=") {
+		target = strings.TrimSpace(target[4:])
+	}
+	return strings.Trim(target, `'"`)
+}
+
+// RedirectMode the redirect mode of the request
+type RedirectMode string
+
+const (
+	// RedirectFollow follows the redirects, the default mode
+	RedirectFollow RedirectMode = "follow"
+	// RedirectManual returns the redirect response as-is
+	RedirectManual RedirectMode = "manual"
+	// RedirectError returns ErrRedirect when the redirect response received
+	RedirectError RedirectMode = "error"
+)
+
+// defaultMaxRedirects the default maximum number of redirects to follow
+const defaultMaxRedirects = 10
+
+// ErrRedirect the redirect is not allowed by the RedirectError mode
+var ErrRedirect = errors.New("redirect is not allowed")
+
+// ErrTooManyRedirects the redirects exceed the maximum, see FetchOptions.MaxRedirects
+var ErrTooManyRedirects = errors.New("too many redirects")
+
+// RedirectsError the redirects exceed the maximum, the Chain is the URLs
+// from the original request to the rejected redirect target.
+type RedirectsError struct {
+	Max   int
+	Chain []string
+}
+
+func (e *RedirectsError) Error() string {
+	return fmt.Sprintf("stopped after %d redirects: %s", e.Max, strings.Join(e.Chain, " -> "))
+}
+
+func (e *RedirectsError) Unwrap() error { return ErrTooManyRedirects }
+
+var redirectModeKey byte
+
+// WithRedirectMode returns a copy of parent context in which the redirect mode associated with context.
+func WithRedirectMode(ctx context.Context, mode RedirectMode) context.Context {
+	// the mode is per request, do not set on the shared Context
+	return context.WithValue(ctx, &redirectModeKey, mode)
+}
+
+// RedirectModeFromContext returns the redirect mode on context, default is RedirectFollow.
+func RedirectModeFromContext(ctx context.Context) RedirectMode {
+	if mode, ok := ctx.Value(&redirectModeKey).(RedirectMode); ok {
+		return mode
+	}
+	return RedirectFollow
+}
+
+// CheckRedirect the http.Client CheckRedirect policy with the request context RedirectMode,
+// the redirect loop stops after 10 redirects.
+func CheckRedirect(req *http.Request, via []*http.Request) error {
+	return checkRedirect(req, via, defaultMaxRedirects)
+}
+
+// checkRedirect the CheckRedirect with the maximum number of redirects, the negative is zero
+func checkRedirect(req *http.Request, via []*http.Request, maxRedirects int) error {
+	maxRedirects = max(maxRedirects, 0)
+	switch RedirectModeFromContext(req.Context()) {
+	case RedirectManual:
+		return http.ErrUseLastResponse
+	case RedirectError:
+		return fmt.Errorf("%w: %s", ErrRedirect, req.URL)
+	}
+	if len(via) > maxRedirects {
+		chain := make([]string, 0, len(via)+1)
+		for _, r := range via {
+			chain = append(chain, r.URL.String())
+		}
+		return &RedirectsError{Max: maxRedirects, Chain: append(chain, req.URL.String())}
+	}
+	return nil
+}
+
+// Redirects returns the URLs redirected from before the final response in order,
+// include the HTTP redirects and the meta refreshes. The final URL is the res.Request.URL.
+func Redirects(res *http.Response) []string {
+	var urls []string
+	for req := res.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		if req.Response.Request == nil {
+			break
+		}
+		urls = append(urls, req.Response.Request.URL.String())
+	}
+	slices.Reverse(urls)
+	return urls
+}
@@ -0,0 +1,226 @@
+package ski
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetaRefresh(t *testing.T) {
+	t.Parallel()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/meta", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = fmt.Fprint(w, `<html><head><meta http-equiv="Refresh" content="0; URL='/js'"></head></html>`)
+	})
+	mux.HandleFunc("/js", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = fmt.Fprint(w, `<html><head><script>window.location.href = "/final";</script></head></html>`)
+	})
+	mux.HandleFunc("/final", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = fmt.Fprint(w, `<html><body>final</body></html>`)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = fmt.Fprint(w, `<meta http-equiv="refresh" content="1;url=/loop">`)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	get := func(fetch Fetch, path string) string {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		res, err := fetch.Do(req)
+		if !assert.NoError(t, err) {
+			return ""
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		return string(body)
+	}
+
+	fetch := NewFetcher(FetchOptions{MaxMetaRefresh: 3})
+	assert.Equal(t, `<html><body>final</body></html>`, get(fetch, "/meta"))
+	assert.Equal(t, `<meta http-equiv="refresh" content="1;url=/loop">`, get(fetch, "/loop"))
+
+	fetch = NewFetcher(FetchOptions{MaxMetaRefresh: 1})
+	assert.Contains(t, get(fetch, "/meta"), "window.location.href")
+
+	fetch = NewFetcher(FetchOptions{})
+	assert.Contains(t, get(fetch, "/meta"), `http-equiv="Refresh"`)
+}
+
+func TestRedirectMode(t *testing.T) {
+	t.Parallel()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/final", http.StatusFound)
+	})
+	mux.HandleFunc("/final", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, "final")
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	var hops int
+	fetch := NewFetcher(FetchOptions{})
+	custom := NewFetcher(FetchOptions{CheckRedirect: func(_ *http.Request, via []*http.Request) error {
+		hops = len(via)
+		if len(via) >= 3 {
+			return errors.New("custom redirect limit")
+		}
+		return nil
+	}})
+	do := func(fetch *Fetcher, path string, mode RedirectMode) (*http.Response, error) {
+		ctx := context.Background()
+		if mode != "" {
+			ctx = WithRedirectMode(ctx, mode)
+		}
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+path, nil)
+		res, err := fetch.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		t.Cleanup(func() { _ = res.Body.Close() })
+		return res, nil
+	}
+
+	res, err := do(fetch, "/redirect", "")
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	}
+
+	res, err = do(fetch, "/redirect", RedirectManual)
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusFound, res.StatusCode)
+		assert.Equal(t, "/final", res.Header.Get("Location"))
+	}
+
+	_, err = do(fetch, "/redirect", RedirectError)
+	assert.ErrorIs(t, err, ErrRedirect)
+
+	_, err = do(fetch, "/loop", RedirectFollow)
+	assert.ErrorContains(t, err, "stopped after 10 redirects")
+
+	_, err = do(custom, "/loop", "")
+	assert.ErrorContains(t, err, "custom redirect limit")
+	assert.Equal(t, 3, hops)
+
+	res, err = do(custom, "/loop", RedirectManual)
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusFound, res.StatusCode)
+	}
+}
+
+func TestMaxRedirects(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		if n < 5 {
+			http.Redirect(w, r, "/"+strconv.Itoa(n+1), http.StatusFound)
+			return
+		}
+		_, _ = fmt.Fprint(w, "final")
+	}))
+	defer ts.Close()
+
+	do := func(fetch Fetch) error {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"/0", nil)
+		res, err := fetch.Do(req)
+		if err != nil {
+			return err
+		}
+		return res.Body.Close()
+	}
+
+	err := do(NewFetcher(FetchOptions{MaxRedirects: 3}))
+	assert.ErrorIs(t, err, ErrTooManyRedirects)
+	var re *RedirectsError
+	if assert.ErrorAs(t, err, &re) {
+		assert.Equal(t, 3, re.Max)
+		assert.Equal(t, []string{ts.URL + "/0", ts.URL + "/1", ts.URL + "/2", ts.URL + "/3", ts.URL + "/4"}, re.Chain)
+		assert.ErrorContains(t, err, "stopped after 3 redirects: "+ts.URL+"/0 -> "+ts.URL+"/1")
+	}
+
+	err = do(NewFetcher(FetchOptions{MaxRedirects: -1}))
+	if assert.ErrorAs(t, err, &re) {
+		assert.Equal(t, 0, re.Max)
+		assert.ErrorContains(t, err, "stopped after 0 redirects: "+ts.URL+"/0 -> "+ts.URL+"/1")
+	}
+
+	assert.NoError(t, do(NewFetcher(FetchOptions{MaxRedirects: 5})))
+	assert.NoError(t, do(NewFetcher(FetchOptions{})))
+	assert.NoError(t, do(NewFetch()))
+
+	// the redirect chain is not followed again by the retries
+	var hits atomic.Int32
+	retry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		http.Redirect(w, r, "/next"+r.URL.Path, http.StatusFound)
+	}))
+	defer retry.Close()
+	req, _ := http.NewRequest(http.MethodGet, retry.URL, nil)
+	_, err = NewFetcher(FetchOptions{
+		MaxRedirects: 2,
+		MaxRetries:   3,
+		RetryBackoff: RetryBackoff{Base: time.Millisecond},
+	}).Do(req)
+	assert.ErrorIs(t, err, ErrTooManyRedirects)
+	assert.Equal(t, int32(3), hits.Load())
+}
+
+func TestRedirects(t *testing.T) {
+	t.Parallel()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/first", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/second", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/second", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/final", http.StatusFound)
+	})
+	mux.HandleFunc("/meta", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = fmt.Fprint(w, `<meta http-equiv="refresh" content="0;url=/first">`)
+	})
+	mux.HandleFunc("/final", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, "final")
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	fetch := NewFetcher(FetchOptions{MaxMetaRefresh: 1})
+	do := func(path string) *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		res, err := fetch.Do(req)
+		if !assert.NoError(t, err) {
+			return nil
+		}
+		t.Cleanup(func() { _ = res.Body.Close() })
+		return res
+	}
+
+	if res := do("/first"); res != nil {
+		assert.Equal(t, ts.URL+"/final", res.Request.URL.String())
+		assert.Equal(t, []string{ts.URL + "/first", ts.URL + "/second"}, Redirects(res))
+	}
+	if res := do("/meta"); res != nil {
+		assert.Equal(t, ts.URL+"/final", res.Request.URL.String())
+		assert.Equal(t, []string{ts.URL + "/meta", ts.URL + "/first", ts.URL + "/second"}, Redirects(res))
+	}
+	if res := do("/final"); res != nil {
+		assert.Empty(t, Redirects(res))
+	}
+}
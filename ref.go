@@ -0,0 +1,61 @@
+package ski
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WithDefinition registers the named sub schema, which can be reused by `$ref: name`
+// without duplication. The definitions can reference each other, the reference cycle
+// is reported as the compile error.
+//
+//	WithDefinition("author", `
+//	$map:
+//	  name: { $css: .name }`)
+//	schema:
+//	  $map:
+//	    author: { $ref: author }
+//	    editor: { $ref: author }
+func WithDefinition(name, schema string) Option {
+	return func(c *compiler) {
+		if c.definitions == nil {
+			c.definitions = make(map[string]string)
+		}
+		c.definitions[name] = schema
+	}
+}
+
+// compileRef compiles the referenced definition, the definitions on the
+// reference path are tracked to detect the cycle.
+func (c compiler) compileRef(k, v *yaml.Node) (Executor, error) {
+	name := v.Value
+	if v.Kind != yaml.ScalarNode || name == "" {
+		return nil, c.newError("ref", k, errors.New("expected the definition name"))
+	}
+	schema, ok := c.definitions[name]
+	if !ok {
+		return nil, c.newError("ref", k, fmt.Errorf("definition %s not found", name))
+	}
+	if slices.Contains(c.refs, name) {
+		return nil, c.newError("ref", k, fmt.Errorf("reference cycle %s -> %s", strings.Join(c.refs, " -> "), name))
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(schema), &node); err != nil {
+		return nil, c.newError("ref", k, fmt.Errorf("definition %s: %w", name, syntaxError(schema, err)))
+	}
+	root := documentContent(&node)
+	if root == nil || root.Kind == 0 {
+		return nil, c.newError("ref", k, fmt.Errorf("definition %s is empty", name))
+	}
+	c.refs = append(slices.Clip(c.refs), name)
+	exec, err := c.compileNode(root)
+	if err != nil {
+		return nil, fmt.Errorf("definition %s: %w", name, err)
+	}
+	return c.piping(exec), nil
+}
@@ -0,0 +1,50 @@
+package ski
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefinition(t *testing.T) {
+	t.Parallel()
+	author := WithDefinition("author", `
+$map:
+  name:
+    $string.strip_prefix: "@"
+  profile:
+    $ref: profile`)
+	profile := WithDefinition("profile", `$string.replace: ["^@(.+)$", "https://example.com/$1"]`)
+
+	exec, err := Compile(`
+$map:
+  author:
+    $ref: author
+  editor:
+    $ref: author`, author, profile)
+	if assert.NoError(t, err) {
+		v, err := exec.Exec(context.Background(), "@foo")
+		if assert.NoError(t, err) {
+			person := map[string]any{"name": "foo", "profile": "https://example.com/foo"}
+			assert.Equal(t, map[string]any{"author": person, "editor": person}, v)
+		}
+	}
+
+	_, err = Compile(`$ref: a`,
+		WithDefinition("a", `$map: { b: { $ref: b } }`),
+		WithDefinition("b", `$map: { a: { $ref: a } }`))
+	assert.ErrorContains(t, err, "reference cycle a -> b -> a")
+
+	_, err = Compile(`$map: { self: { $ref: self } }`, WithDefinition("self", `$ref: self`))
+	assert.ErrorContains(t, err, "reference cycle self -> self")
+
+	_, err = Compile(`$ref: missing`)
+	assert.ErrorContains(t, err, "line 1 column 1 ref: definition missing not found")
+
+	_, err = Compile(`$ref: empty`, WithDefinition("empty", ``))
+	assert.ErrorContains(t, err, "definition empty is empty")
+
+	_, err = Compile(`$ref: bad`, WithDefinition("bad", `$unknown:`))
+	assert.ErrorContains(t, err, "definition bad: line 1 column 1 executor not found")
+}
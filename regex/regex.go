@@ -17,6 +17,8 @@ func init() {
 	ski.Register("regex.replace", new_replace())
 	ski.Register("regex.match", new_match())
 	ski.Register("regex.assert", new_assert())
+	ski.Register("regex.group", new_group(false))
+	ski.Register("regex.groups", new_group(true))
 }
 
 type tokenState int
@@ -120,6 +122,9 @@ func (r _match) Exec(_ context.Context, arg any) (any, error) {
 	case string:
 		str = t
 	case []string:
+		if len(t) == 0 {
+			return nil, nil
+		}
 		str = t[0]
 	case fmt.Stringer:
 		str = t.String()
@@ -162,6 +167,98 @@ func (r _match) findAllString(s string) []string {
 	return matches
 }
 
+// _group returns the first capture group of the match, or the whole match when no groups.
+// If the pattern has the named groups, returns the object of the group names and values.
+// The regex.group returns the first match, the regex.groups returns all matches.
+// The Iterator and []string are matched element-wise.
+type _group struct {
+	*regexp2.Regexp
+	names []string // the named groups
+	all   bool
+}
+
+func new_group(all bool) ski.NewExecutor {
+	return ski.StringExecutor(func(str string) (ski.Executor, error) {
+		re, _, _, _, err := Compile(str)
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, name := range re.GetGroupNames() {
+			if _, err = strconv.Atoi(name); err != nil {
+				names = append(names, name)
+			}
+		}
+		return _group{re, names, all}, nil
+	})
+}
+
+func (g _group) Exec(ctx context.Context, arg any) (any, error) {
+	switch t := arg.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return g.match(t)
+	case fmt.Stringer:
+		return g.match(t.String())
+	case []string:
+		return g.Exec(ctx, ski.NewIterator(t))
+	case ski.Iterator:
+		ret := make([]any, 0, t.Len())
+		for i := 0; i < t.Len(); i++ {
+			v, err := g.Exec(ctx, t.At(i))
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, v)
+		}
+		return ski.NewIterator(ret), nil
+	default:
+		return nil, fmt.Errorf("regex.group unsupported type %T", arg)
+	}
+}
+
+func (g _group) match(s string) (any, error) {
+	m, err := g.FindStringMatch(s)
+	if err != nil || m == nil {
+		return nil, err
+	}
+	if !g.all {
+		return g.value(m), nil
+	}
+	var ret []any
+	for m != nil {
+		ret = append(ret, g.value(m))
+		if m, err = g.FindNextMatch(m); err != nil {
+			return nil, err
+		}
+	}
+	return ski.NewIterator(ret), nil
+}
+
+// value returns the named groups object, the first group or the whole match,
+// the group which does not participate in the match is nil.
+func (g _group) value(m *regexp2.Match) any {
+	if len(g.names) > 0 {
+		obj := make(map[string]any, len(g.names))
+		for _, name := range g.names {
+			obj[name] = groupValue(m.GroupByName(name))
+		}
+		return obj
+	}
+	if groups := m.Groups(); len(groups) > 1 {
+		return groupValue(&groups[1])
+	}
+	return m.String()
+}
+
+func groupValue(group *regexp2.Group) any {
+	if group == nil || len(group.Captures) == 0 {
+		return nil
+	}
+	return group.String()
+}
+
 type _assert struct {
 	*regexp2.Regexp
 	err error
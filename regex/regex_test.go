@@ -71,6 +71,18 @@ func TestMatch(t *testing.T) {
 			assert.Equal(t, testCase.want, v)
 		})
 	}
+
+	exec, err := new_match()(ski.String(`/\d+/`))
+	if assert.NoError(t, err) {
+		for _, arg := range []any{nil, []string(nil)} {
+			assert.NotPanics(t, func() {
+				v, err := exec.Exec(context.Background(), arg)
+				if assert.NoError(t, err) {
+					assert.Nil(t, v)
+				}
+			})
+		}
+	}
 }
 
 func TestAssert(t *testing.T) {
@@ -101,3 +113,42 @@ func TestAssert(t *testing.T) {
 		})
 	}
 }
+
+func TestGroup(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		re   string
+		all  bool
+		arg  any
+		want any
+	}{
+		{`/\/item\/(\d+)/`, false, `https://example.com/item/114/514`, "114"},
+		{`/\d+/`, false, `id: 114, 514`, "114"},
+		{`/id=(\d+)/`, false, `name=foo`, nil},
+		{`/(a)|(b)/`, false, `b`, nil},
+		{`/ID=(\d+)/i`, true, `id=1&id=2&ID=3`, ski.NewIterator([]any{"1", "2", "3"})},
+		{`/\d+/`, true, `1a22b333`, ski.NewIterator([]any{"1", "22", "333"})},
+		{`/x/`, true, `abc`, nil},
+		{`/(\d+)/`, false, []string{"a1", "b", "c3"}, ski.NewIterator([]any{"1", nil, "3"})},
+		{`/(?<key>\w+)=(?<value>\w*)/`, false, `a=1&b=`, map[string]any{"key": "a", "value": "1"}},
+		{`/(?<key>\w+)=(?<value>\w*)/`, true, `a=1&b=`, ski.NewIterator([]any{
+			map[string]any{"key": "a", "value": "1"},
+			map[string]any{"key": "b", "value": ""},
+		})},
+		{`/(?<year>\d{4})(-(?<month>\d{2}))?/`, false, `2024`, map[string]any{"year": "2024", "month": nil}},
+		{`/(\w+)@(?<domain>[\w.]+)/`, false, `foo@example.com`, map[string]any{"domain": "example.com"}},
+		{`/(\d+)/`, false, nil, nil},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.re, func(t *testing.T) {
+			exec, err := new_group(testCase.all)(ski.String(testCase.re))
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, err := exec.Exec(context.Background(), testCase.arg)
+			if assert.NoError(t, err) {
+				assert.Equal(t, testCase.want, v)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package ski
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RetryBackoff the exponential backoff of the retries, the delay of the nth retry
+// is Base * 2^n capped at Max, the Jitter fraction of the delay is randomly subtracted.
+type RetryBackoff struct {
+	// Base the delay of the first retry, default is 500 milliseconds.
+	Base time.Duration `yaml:"base" json:"base"`
+	// Max the maximum delay, default is 30 seconds.
+	Max time.Duration `yaml:"max" json:"max"`
+	// Jitter the fraction (0 to 1) of the delay to be randomly subtracted, zero means no jitter.
+	Jitter float64 `yaml:"jitter" json:"jitter"`
+}
+
+const (
+	defaultRetryBase = 500 * time.Millisecond
+	defaultRetryMax  = 30 * time.Second
+)
+
+func (b RetryBackoff) limits() (base, max time.Duration) {
+	base, max = b.Base, b.Max
+	if base <= 0 {
+		base = defaultRetryBase
+	}
+	if max <= 0 {
+		max = defaultRetryMax
+	}
+	return
+}
+
+// Delay returns the delay of the nth (zero-based) retry.
+func (b RetryBackoff) Delay(n int) time.Duration {
+	base, max := b.limits()
+	delay := max
+	if n < 32 && base<<n > 0 && base<<n < max {
+		delay = base << n
+	}
+	if b.Jitter > 0 {
+		delay -= time.Duration(rand.Float64() * min(b.Jitter, 1) * float64(delay))
+	}
+	return delay
+}
+
+// retryable reports whether the request can be retried after the response or error.
+// Only the network errors are retried, the others will not change on retry.
+func retryable(res *http.Response, err error) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+			errors.Is(err, ErrRedirect) || errors.Is(err, ErrTooManyRedirects) || errors.Is(err, ErrInterceptor) {
+			return false
+		}
+		// the http.Client wraps the errors with the *url.Error, which is also a net.Error
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		var netErr net.Error
+		return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+	}
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter parses the Retry-After header of the 429 and 503 responses,
+// the value is the seconds or the HTTP date.
+func retryAfter(res *http.Response) (time.Duration, bool) {
+	if res == nil || (res.StatusCode != http.StatusTooManyRequests && res.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+	value := res.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// retry dispatches the request, retries up to FetchOptions.MaxRetries times with the backoff.
+// The request with the body that cannot be rewound by GetBody is not retried.
+// Stop retrying and returns the last result if the delay exceeds the context deadline.
+func (f *Fetcher) retry(req *http.Request) (*http.Response, error) {
+	res, err := f.dispatch(req)
+	for n := 0; n < f.opt.MaxRetries && retryable(res, err); n++ {
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			break
+		}
+
+		delay := f.opt.RetryBackoff.Delay(n)
+		if after, ok := retryAfter(res); ok {
+			_, limit := f.opt.RetryBackoff.limits()
+			delay = min(after, limit)
+		}
+		ctx := req.Context()
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			break
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if res != nil {
+				_ = res.Body.Close()
+			}
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		next := req.Clone(ctx)
+		if req.GetBody != nil {
+			if next.Body, err = req.GetBody(); err != nil {
+				if res != nil {
+					_ = res.Body.Close()
+				}
+				return nil, err
+			}
+		}
+		if res != nil {
+			_ = res.Body.Close()
+		}
+		res, err = f.dispatch(next)
+	}
+	return res, err
+}
@@ -0,0 +1,163 @@
+package ski
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	t.Parallel()
+	backoff := RetryBackoff{Base: 100 * time.Millisecond, Max: time.Second}
+	for n, want := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		assert.Equal(t, want*time.Millisecond, backoff.Delay(n))
+	}
+	assert.Equal(t, defaultRetryBase, RetryBackoff{}.Delay(0))
+	assert.Equal(t, defaultRetryMax, RetryBackoff{}.Delay(100))
+
+	backoff.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		delay := backoff.Delay(1)
+		assert.GreaterOrEqual(t, delay, 100*time.Millisecond)
+		assert.LessOrEqual(t, delay, 200*time.Millisecond)
+	}
+}
+
+func TestFetcherRetry(t *testing.T) {
+	t.Parallel()
+	var (
+		mu    sync.Mutex
+		times = make(map[string][]time.Time)
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		times[r.URL.Path] = append(times[r.URL.Path], time.Now())
+		attempts := len(times[r.URL.Path])
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/flaky":
+			if attempts <= 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		case "/retry-after":
+			if attempts == 1 {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+		case "/down":
+			w.Header().Set("Retry-After", "10")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		case "/reset":
+			if attempts == 1 {
+				conn, _, _ := w.(http.Hijacker).Hijack()
+				_ = conn.Close()
+				return
+			}
+		case "/redirect":
+			http.Redirect(w, r, "/", http.StatusFound)
+			return
+		case "/post":
+			if attempts == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			_, _ = io.Copy(w, r.Body)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(ts.Close)
+
+	fetch := NewFetcher(FetchOptions{MaxRetries: 3, RetryBackoff: RetryBackoff{Base: 50 * time.Millisecond}})
+	do := func(ctx context.Context, method, path, body string) (int, error) {
+		req, _ := http.NewRequestWithContext(ctx, method, ts.URL+path, strings.NewReader(body))
+		res, err := fetch.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		return res.StatusCode, res.Body.Close()
+	}
+	gaps := func(path string) []time.Duration {
+		mu.Lock()
+		defer mu.Unlock()
+		ret := make([]time.Duration, 0, len(times[path]))
+		for i := 1; i < len(times[path]); i++ {
+			ret = append(ret, times[path][i].Sub(times[path][i-1]))
+		}
+		return ret
+	}
+
+	t.Run("backoff", func(t *testing.T) {
+		t.Parallel()
+		status, err := do(context.Background(), http.MethodGet, "/flaky", "")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		delays := gaps("/flaky")
+		if assert.Len(t, delays, 3) {
+			for i, want := range []time.Duration{50, 100, 200} {
+				assert.GreaterOrEqual(t, delays[i], want*time.Millisecond)
+				assert.Less(t, delays[i], want*time.Millisecond+100*time.Millisecond)
+			}
+		}
+	})
+
+	t.Run("retry after", func(t *testing.T) {
+		t.Parallel()
+		status, err := do(context.Background(), http.MethodGet, "/retry-after", "")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		delays := gaps("/retry-after")
+		if assert.Len(t, delays, 1) {
+			assert.GreaterOrEqual(t, delays[0], time.Second)
+		}
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		status, err := do(ctx, http.MethodGet, "/down", "")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		assert.Len(t, gaps("/down"), 0)
+	})
+
+	t.Run("body", func(t *testing.T) {
+		t.Parallel()
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/post", strings.NewReader("payload"))
+		res, err := fetch.Do(req)
+		if assert.NoError(t, err) {
+			defer res.Body.Close()
+			body, err := io.ReadAll(res.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, "payload", string(body))
+		}
+	})
+
+	t.Run("network", func(t *testing.T) {
+		t.Parallel()
+		status, err := do(context.Background(), http.MethodGet, "/reset", "")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Len(t, gaps("/reset"), 1)
+	})
+
+	t.Run("not retryable", func(t *testing.T) {
+		t.Parallel()
+		_, err := do(WithRedirectMode(context.Background(), RedirectError), http.MethodGet, "/redirect", "")
+		assert.ErrorIs(t, err, ErrRedirect)
+		assert.Len(t, gaps("/redirect"), 0)
+	})
+}
@@ -5,10 +5,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html"
 	"log/slog"
+	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/spf13/cast"
+	"go.opentelemetry.io/otel/trace"
 	"gopkg.in/yaml.v3"
 )
 
@@ -16,12 +22,33 @@ func init() {
 	Register("kind", new_kind())
 	Register("map", new_map)
 	Register("each", new_each)
+	Register("list", new_list)
+	Register("slice", new_slice)
+	Register("unique", new_unique)
+	Register("keys", new_keys())
+	Register("index", new_index)
 	Register("pipe", new_pipe)
 	Register("or", new_or)
+	Register("first", new_first)
+	Register("default", new_default)
+	Register("required", new_required)
 	Register("debug", new_debug)
+	Register("transform", new_transform())
+	Register("duration", new_duration)
+	Register("date", new_date)
+	Register("number", new_number)
+	Register("template", new_template)
 	Register("string.join", new_string_join)
+	Register("string.trim", new_string_trim)
+	Register("string.collapse", new_string_collapse)
+	Register("string.strip_prefix", new_string_strip_prefix)
+	Register("string.strip_suffix", new_string_strip_suffix)
+	Register("string.replace", new_string_replace)
 	Register("json.parse", new_json_parse)
+	Register("html.unescape", new_html_unescape)
 	Register("json.string", new_json_string)
+	Register("js.var", new_js_var)
+	Register("paginate", new_paginate)
 }
 
 // Iterator is an interface for iterators
@@ -58,13 +85,19 @@ const (
 )
 
 func new_kind() NewExecutor {
-	return StringExecutor(func(str string) (Executor, error) {
+	return func(args ...Executor) (Executor, error) {
+		if len(args) == 0 {
+			return nil, errors.New("needs 1 string argument")
+		}
 		var k Kind
-		if err := k.UnmarshalText([]byte(str)); err != nil {
+		if err := k.UnmarshalText([]byte(ExecToString(args[0]))); err != nil {
 			return nil, err
 		}
-		return k, nil
-	})
+		if len(args) == 1 {
+			return k, nil
+		}
+		return new_strip_kind(k, args[1:]...)
+	}
 }
 
 var kindNames = [...]string{
@@ -106,7 +139,7 @@ func (k *Kind) UnmarshalText(text []byte) error {
 func (k Kind) Exec(_ context.Context, v any) (any, error) {
 	switch k {
 	case KindBool:
-		return cast.ToBoolE(v)
+		return toBool(v)
 	case KindInt:
 		return cast.ToInt32E(v)
 	case KindInt64:
@@ -123,8 +156,14 @@ func (k Kind) Exec(_ context.Context, v any) (any, error) {
 }
 
 type compiler struct {
-	exec Executor
-	meta func(node *yaml.Node, exec Executor, isParser bool) Executor
+	exec        Executor
+	meta        func(node *yaml.Node, exec Executor, isParser bool) Executor
+	tracer      trace.Tracer
+	explain     bool
+	overrides   []string
+	definitions map[string]string
+	// refs the definitions on the current reference path
+	refs []string
 }
 
 func (c compiler) newError(message string, node *yaml.Node, err error) error {
@@ -160,6 +199,9 @@ func (c compiler) piping(args []Executor) Executor {
 // compileExecutor return the Executor with the key and values
 func (c compiler) compileExecutor(k, v *yaml.Node) (Executor, error) {
 	key := strings.TrimPrefix(k.Value, "$")
+	if key == "ref" {
+		return c.compileRef(k, v)
+	}
 	init, ok := GetExecutor(key)
 	if !ok {
 		return nil, c.newError("executor not found", k, errors.New(key))
@@ -172,6 +214,12 @@ func (c compiler) compileExecutor(k, v *yaml.Node) (Executor, error) {
 	if err != nil {
 		return nil, c.newError(key, k, err)
 	}
+	if c.tracer != nil {
+		exec = _trace{exec, c.tracer, key, k.Line, k.Column}
+	}
+	if c.explain {
+		exec = _explain{exec, key, k.Line, k.Column}
+	}
 	if c.meta != nil {
 		return c.meta(k, exec, false), nil
 	}
@@ -273,12 +321,35 @@ func Compile(str string, opts ...Option) (Executor, error) {
 	for _, opt := range opts {
 		opt(c)
 	}
+	if len(c.overrides) > 0 {
+		var err error
+		if str, err = Merge(str, c.overrides...); err != nil {
+			return nil, err
+		}
+	}
 	if err := yaml.Unmarshal([]byte(str), c); err != nil {
-		return nil, err
+		return nil, syntaxError(str, err)
 	}
 	return c.exec, nil
 }
 
+var yamlLineRegexp = regexp.MustCompile(`^yaml: line (\d+):`)
+
+// syntaxError appends the source line to the YAML syntax error, eg:
+// `yaml: line 3: mapping values are not allowed in this context, near "c: d"`
+func syntaxError(source string, err error) error {
+	m := yamlLineRegexp.FindStringSubmatch(err.Error())
+	if m == nil {
+		return err
+	}
+	n, _ := strconv.Atoi(m[1])
+	lines := strings.Split(source, "\n")
+	if n < 1 || n > len(lines) {
+		return err
+	}
+	return fmt.Errorf("%w, near %q", err, strings.TrimSpace(lines[n-1]))
+}
+
 // String the Executor for string value
 type String string
 
@@ -297,20 +368,40 @@ func new_map(args ...Executor) (Executor, error) {
 }
 
 func (m _map) Exec(ctx context.Context, arg any) (any, error) {
-	var ret map[string]any
-
+	var (
+		ret  map[string]any
+		errs []error
+	)
+
+	type field struct {
+		key   string
+		value any
+		err   error
+		ok    bool
+	}
+	fields := make([]field, len(m)/2)
 	exec := func(a any) {
-		for i := 0; i < len(m); i += 2 {
-			k, err := m[i].Exec(ctx, a)
+		parallel(ctx, len(fields), func(i int) {
+			fields[i] = field{}
+			k, err := m[i*2].Exec(ctx, a)
 			if err != nil {
-				continue
+				return
 			}
 			ks, err := cast.ToStringE(k)
 			if err != nil {
+				return
+			}
+			v, err := execField(ctx, m[i*2+1], a, ks)
+			fields[i] = field{ks, v, err, true}
+		})
+		for _, f := range fields {
+			if !f.ok {
 				continue
 			}
-			v, _ := m[i+1].Exec(ctx, a)
-			ret[ks] = v
+			if errors.Is(f.err, ErrRequired) {
+				errs = append(errs, &FieldError{Path: f.key, Err: f.err})
+			}
+			ret[f.key] = f.value
 		}
 	}
 
@@ -320,12 +411,11 @@ func (m _map) Exec(ctx context.Context, arg any) (any, error) {
 		for i := 0; i < s.Len(); i++ {
 			exec(s.At(i))
 		}
-		return ret, nil
 	default:
 		ret = make(map[string]any, len(m)/2)
 		exec(arg)
-		return ret, nil
 	}
+	return ret, errors.Join(errs...)
 }
 
 type _each struct{ Executor }
@@ -341,13 +431,18 @@ func (each _each) Exec(ctx context.Context, arg any) (any, error) {
 	switch s := arg.(type) {
 	case Iterator:
 		ret := make([]any, 0, s.Len())
+		var errs []error
 		for i := 0; i < s.Len(); i++ {
-			v, _ := each.Executor.Exec(ctx, s.At(i))
+			field := strconv.Itoa(i)
+			v, err := execField(ctx, each.Executor, s.At(i), field)
+			if errors.Is(err, ErrRequired) {
+				errs = append(errs, &FieldError{Path: field, Err: err})
+			}
 			ret = append(ret, v)
 		}
-		return NewIterator(ret), nil
+		return NewIterator(ret), errors.Join(errs...)
 	default:
-		v, err := each.Executor.Exec(ctx, arg)
+		v, err := execField(ctx, each.Executor, arg, "0")
 		if err != nil {
 			return nil, nil
 		}
@@ -355,6 +450,235 @@ func (each _each) Exec(ctx context.Context, arg any) (any, error) {
 	}
 }
 
+// _list coerce the value into an Iterator, a single value will be
+// wrapped into one-element Iterator, nil will be an empty Iterator.
+type _list struct{}
+
+func new_list(_ ...Executor) (Executor, error) { return _list{}, nil }
+
+func (_list) Exec(_ context.Context, arg any) (any, error) {
+	switch s := arg.(type) {
+	case Iterator:
+		return s, nil
+	case []any:
+		return NewIterator(s), nil
+	case []string:
+		return NewIterator(s), nil
+	case nil:
+		return NewIterator([]any{}), nil
+	default:
+		return NewIterator([]any{s}), nil
+	}
+}
+
+// _slice skip the offset elements and take the limit elements of the Iterator,
+// the negative limit takes the last elements, zero limit means no limit.
+// The value is coerced into an Iterator as the list. eg:
+//
+//	$slice: 3 # the first 3 elements
+//	$slice:
+//	  offset: 1 # skip the header row
+//	  limit: -2 # the last 2 elements
+type _slice struct{ offset, limit int }
+
+func new_slice(args ...Executor) (Executor, error) {
+	var (
+		s   _slice
+		err error
+	)
+	switch len(args) {
+	case 1:
+		if s.limit, err = cast.ToIntE(ExecToString(args[0])); err != nil {
+			return nil, fmt.Errorf("invalid slice limit %s", ExecToString(args[0]))
+		}
+		return s, nil
+	case 2, 4:
+		for i := 0; i < len(args); i += 2 {
+			key, value := ExecToString(args[i]), ExecToString(args[i+1])
+			n, err := cast.ToIntE(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid slice %s %s", key, value)
+			}
+			switch key {
+			case "offset":
+				if n < 0 {
+					return nil, fmt.Errorf("slice offset %d must not be negative", n)
+				}
+				s.offset = n
+			case "limit":
+				s.limit = n
+			default:
+				return nil, fmt.Errorf("unknown slice option %s", key)
+			}
+		}
+		return s, nil
+	default:
+		return nil, errors.New("slice needs the limit or the offset and limit mapping")
+	}
+}
+
+func (s _slice) Exec(ctx context.Context, arg any) (any, error) {
+	v, _ := _list{}.Exec(ctx, arg)
+	list := v.(Iterator)
+	start, end := min(s.offset, list.Len()), list.Len()
+	switch {
+	case s.limit > 0:
+		end = min(start+s.limit, end)
+	case s.limit < 0:
+		start = max(start, end+s.limit)
+	}
+	ret := make([]any, 0, end-start)
+	for i := start; i < end; i++ {
+		ret = append(ret, list.At(i))
+	}
+	return NewIterator(ret), nil
+}
+
+// _unique removes the duplicate elements of the Iterator and preserves the first-seen order,
+// the objects are deduplicated by the key field if present, the objects without the key are kept.
+// The value is coerced into an Iterator as the list. eg:
+//
+//	$unique:    # the values
+//	$unique: id # the objects by the id field
+type _unique string
+
+func new_unique(args ...Executor) (Executor, error) {
+	if len(args) > 1 {
+		return nil, errors.New("unique needs the key field or no parameter")
+	}
+	if len(args) == 1 {
+		return _unique(ExecToString(args[0])), nil
+	}
+	return _unique(""), nil
+}
+
+func (key _unique) Exec(ctx context.Context, arg any) (any, error) {
+	v, _ := _list{}.Exec(ctx, arg)
+	list := v.(Iterator)
+	seen := make(map[any]struct{}, list.Len())
+	ret := make([]any, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		e := list.At(i)
+		k := e
+		if key != "" {
+			obj, ok := e.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("unique by %s unexpected type %T", key, e)
+			}
+			if k, ok = obj[string(key)]; !ok {
+				ret = append(ret, e)
+				continue
+			}
+		}
+		if k != nil && !reflect.TypeOf(k).Comparable() {
+			k = fmt.Sprintf("%T:%v", k, k)
+		}
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		ret = append(ret, e)
+	}
+	return NewIterator(ret), nil
+}
+
+// _keys normalize the object keys casing recursively, include the objects in Iterator.
+type _keys func(string) string
+
+func new_keys() NewExecutor {
+	return StringExecutor(func(str string) (Executor, error) {
+		switch str {
+		case "lower":
+			return _keys(strings.ToLower), nil
+		case "upper":
+			return _keys(strings.ToUpper), nil
+		case "snake":
+			return _keys(toSnake), nil
+		default:
+			return nil, fmt.Errorf("unknown keys casing %s", str)
+		}
+	})
+}
+
+func (k _keys) Exec(ctx context.Context, arg any) (any, error) {
+	switch s := arg.(type) {
+	case map[string]any:
+		ret := make(map[string]any, len(s))
+		for key, value := range s {
+			v, _ := k.Exec(ctx, value)
+			ret[k(key)] = v
+		}
+		return ret, nil
+	case Iterator:
+		ret := make([]any, 0, s.Len())
+		for i := 0; i < s.Len(); i++ {
+			v, _ := k.Exec(ctx, s.At(i))
+			ret = append(ret, v)
+		}
+		return NewIterator(ret), nil
+	default:
+		return arg, nil
+	}
+}
+
+// toSnake converts the string to snake_case. eg: "fooBar", "Foo Bar", "foo-bar" to "foo_bar"
+func toSnake(s string) string {
+	var buf strings.Builder
+	runes := []rune(strings.TrimSpace(s))
+	for i, r := range runes {
+		switch {
+		case r == ' ' || r == '-' || r == '_':
+			if buf.Len() > 0 && !strings.HasSuffix(buf.String(), "_") {
+				buf.WriteByte('_')
+			}
+		case unicode.IsUpper(r):
+			if i > 0 && buf.Len() > 0 && !strings.HasSuffix(buf.String(), "_") &&
+				(unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+					(i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				buf.WriteByte('_')
+			}
+			buf.WriteRune(unicode.ToLower(r))
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
+
+// _index inject the element position of the Iterator into each object element,
+// the default field name is "_index".
+type _index string
+
+func new_index(args ...Executor) (Executor, error) {
+	if len(args) > 0 {
+		if name := ExecToString(args[0]); name != "" {
+			return _index(name), nil
+		}
+	}
+	return _index("_index"), nil
+}
+
+func (name _index) Exec(_ context.Context, arg any) (any, error) {
+	s, ok := arg.(Iterator)
+	if !ok {
+		return arg, nil
+	}
+	ret := make([]any, 0, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		v := s.At(i)
+		if m, ok := v.(map[string]any); ok {
+			obj := make(map[string]any, len(m)+1)
+			for k, e := range m {
+				obj[k] = e
+			}
+			obj[string(name)] = i
+			v = obj
+		}
+		ret = append(ret, v)
+	}
+	return NewIterator(ret), nil
+}
+
 // Raw the Executor for raw value, return the original value
 func Raw(arg any) Executor { return _raw{arg} }
 
@@ -367,26 +691,48 @@ type _pipe []Executor
 func new_pipe(args ...Executor) (Executor, error) { return _pipe(args), nil }
 
 func (pipe _pipe) Exec(ctx context.Context, v any) (any, error) {
+	if err := CheckDeadlineBudget(ctx); err != nil {
+		return nil, err
+	}
 	switch len(pipe) {
 	case 0:
 		return nil, nil
 	case 1:
 		return pipe[0].Exec(ctx, v)
 	default:
+		// the partial result with ErrRequired is passed on, the required errors are returned at last
+		var errs []error
 		ret, err := pipe[0].Exec(ctx, v)
-		if err != nil || ret == nil {
-			return nil, err
+		if err != nil {
+			if !errors.Is(err, ErrRequired) {
+				return nil, err
+			}
+			errs = append(errs, err)
+		}
+		if ret == nil {
+			return nil, errors.Join(errs...)
 		}
 		for _, s := range pipe[1:] {
+			if err = CheckDeadlineBudget(ctx); err != nil {
+				return nil, err
+			}
 			ret, err = s.Exec(ctx, ret)
 			if err != nil {
-				return nil, err
+				if !errors.Is(err, ErrRequired) {
+					return nil, err
+				}
+				errs = append(errs, err)
 			}
 		}
-		return ret, nil
+		return ret, errors.Join(errs...)
 	}
 }
 
+// _or returns the first non-nil result of the rules with the same arg, the rule which
+// returns an error is skipped, nil if none. The empty result, eg: "" or the empty Iterator,
+// is accepted, so it suits the rules which return nil if not found, eg: the $jq paths.
+// Use the $first to skip the empty results as well, and to report the errors if all rules fail,
+// eg: the selectors of the several page layouts, the gq "||" alternatives.
 type _or []Executor
 
 func new_or(args ...Executor) (Executor, error) { return _or(args), nil }
@@ -404,6 +750,122 @@ func (or _or) Exec(ctx context.Context, arg any) (any, error) {
 	return nil, nil
 }
 
+// _first tries the candidate rules in order and returns the first non-empty result,
+// the candidate which returns an error is skipped. Unlike the pipe, each candidate is
+// an independent rule with the same arg, eg: the page has several layouts.
+//
+//	$first:
+//	  - - $css: .layout-a .title
+//	    - $string.trim:
+//	  - $css: .layout-b h1
+//
+// If all candidates fail, the errors are returned. See _or for the difference.
+type _first []Executor
+
+func new_first(args ...Executor) (Executor, error) {
+	if len(args) == 0 {
+		return nil, errors.New("first needs at least 1 parameter")
+	}
+	return _first(args), nil
+}
+
+func (first _first) Exec(ctx context.Context, arg any) (any, error) {
+	var (
+		ret  any
+		errs []error
+	)
+	for _, exec := range first {
+		if err := CheckDeadlineBudget(ctx); err != nil {
+			return nil, err
+		}
+		v, err := exec.Exec(ctx, arg)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !isEmpty(v) {
+			return v, nil
+		}
+		ret = v
+	}
+	if len(errs) == len(first) {
+		return nil, errors.Join(errs...)
+	}
+	return ret, nil
+}
+
+// isEmpty reports whether the value is nil, empty string, empty Iterator, slice or map.
+func isEmpty(v any) bool {
+	switch s := v.(type) {
+	case nil:
+		return true
+	case string:
+		return s == ""
+	case Iterator:
+		return s.Len() == 0
+	case []any:
+		return len(s) == 0
+	case []string:
+		return len(s) == 0
+	case map[string]any:
+		return len(s) == 0
+	default:
+		return false
+	}
+}
+
+// _default returns the default value if the value is empty.
+// The first arg extracts the value, the second arg is the default value. eg:
+//
+//	$default:
+//	  - $css: .count
+//	  - 0
+type _default struct{ value, fallback Executor }
+
+func new_default(args ...Executor) (Executor, error) {
+	if len(args) != 2 {
+		return nil, errors.New("default needs 2 parameters")
+	}
+	return _default{args[0], args[1]}, nil
+}
+
+func (d _default) Exec(ctx context.Context, arg any) (any, error) {
+	v, err := d.value.Exec(ctx, arg)
+	if err != nil && !errors.Is(err, ErrRequired) {
+		return nil, err
+	}
+	if isEmpty(v) {
+		return d.fallback.Exec(ctx, arg)
+	}
+	return v, err
+}
+
+// ErrRequired the required value is empty
+var ErrRequired = errors.New("required value is empty")
+
+// _required returns ErrRequired if the value is empty, the errors of
+// the required fields are collected by the map and each, others fields are still extracted.
+// The pipe passes the partial result on and returns the ErrRequired at last.
+type _required struct{ Executor }
+
+func new_required(args ...Executor) (Executor, error) {
+	if len(args) != 1 {
+		return nil, errors.New("required needs 1 parameter")
+	}
+	return _required{args[0]}, nil
+}
+
+func (r _required) Exec(ctx context.Context, arg any) (any, error) {
+	v, err := r.Executor.Exec(ctx, arg)
+	if err != nil {
+		return v, err
+	}
+	if isEmpty(v) {
+		return v, ErrRequired
+	}
+	return v, nil
+}
+
 type _debug string
 
 func new_debug(args ...Executor) (Executor, error) {
@@ -448,18 +910,170 @@ func (sep _string_join) Exec(_ context.Context, arg any) (any, error) {
 	}
 }
 
-type _json_parse struct{}
+// mapString applies the fn to the string, or element-wise to the []string and Iterator
+func mapString(arg any, fn func(string) string) (any, error) {
+	switch s := arg.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return fn(s), nil
+	case []string:
+		ret := make([]string, len(s))
+		for i, v := range s {
+			ret[i] = fn(v)
+		}
+		return ret, nil
+	case Iterator:
+		ret := make([]string, s.Len())
+		for i := range ret {
+			v, err := cast.ToStringE(s.At(i))
+			if err != nil {
+				return nil, err
+			}
+			ret[i] = fn(v)
+		}
+		return NewIterator(ret), nil
+	default:
+		v, err := cast.ToStringE(arg)
+		if err != nil {
+			return nil, err
+		}
+		return fn(v), nil
+	}
+}
+
+// _string_trim removes the leading and trailing characters in the cutset,
+// default is the Unicode white space.
+//
+//	$string.trim: " *"
+type _string_trim string
+
+func new_string_trim(args ...Executor) (Executor, error) {
+	if len(args) == 0 {
+		return _string_trim(""), nil
+	}
+	return _string_trim(ExecToString(args[0])), nil
+}
+
+func (cutset _string_trim) Exec(_ context.Context, arg any) (any, error) {
+	if cutset == "" {
+		return mapString(arg, strings.TrimSpace)
+	}
+	return mapString(arg, func(s string) string { return strings.Trim(s, string(cutset)) })
+}
+
+// _string_collapse trims the string and collapses the runs of white space
+// include the newlines into a single space.
+//
+//	$string.collapse:
+type _string_collapse struct{}
+
+func new_string_collapse(_ ...Executor) (Executor, error) { return _string_collapse{}, nil }
+
+func (_string_collapse) Exec(_ context.Context, arg any) (any, error) {
+	return mapString(arg, func(s string) string { return strings.Join(strings.Fields(s), " ") })
+}
+
+// _string_strip_prefix removes the leading prefix from the string.
+//
+//	$string.strip_prefix: "Price: $"
+type _string_strip_prefix string
+
+func new_string_strip_prefix(args ...Executor) (Executor, error) {
+	if len(args) == 0 {
+		return nil, errors.New("needs 1 string argument")
+	}
+	return _string_strip_prefix(ExecToString(args[0])), nil
+}
+
+func (prefix _string_strip_prefix) Exec(_ context.Context, arg any) (any, error) {
+	return mapString(arg, func(s string) string { return strings.TrimPrefix(s, string(prefix)) })
+}
 
-func new_json_parse(_ ...Executor) (Executor, error) { return _json_parse{}, nil }
+// _string_strip_suffix removes the trailing suffix from the string.
+//
+//	$string.strip_suffix: " USD"
+type _string_strip_suffix string
 
-func (_json_parse) Exec(_ context.Context, v any) (any, error) {
+func new_string_strip_suffix(args ...Executor) (Executor, error) {
+	if len(args) == 0 {
+		return nil, errors.New("needs 1 string argument")
+	}
+	return _string_strip_suffix(ExecToString(args[0])), nil
+}
+
+func (suffix _string_strip_suffix) Exec(_ context.Context, arg any) (any, error) {
+	return mapString(arg, func(s string) string { return strings.TrimSuffix(s, string(suffix)) })
+}
+
+// _string_replace replaces the matches of the regular expression with the replacement,
+// the replacement supports the $1 or ${name} to reference the capture group.
+//
+//	$string.replace: [ '[^\d.]+', '' ]
+type _string_replace struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+func new_string_replace(args ...Executor) (Executor, error) {
+	if len(args) == 0 {
+		return nil, errors.New("needs pattern and replacement arguments")
+	}
+	re, err := regexp.Compile(ExecToString(args[0]))
+	if err != nil {
+		return nil, err
+	}
+	exec := _string_replace{re: re}
+	if len(args) > 1 {
+		exec.replacement = ExecToString(args[1])
+	}
+	return exec, nil
+}
+
+func (r _string_replace) Exec(_ context.Context, arg any) (any, error) {
+	return mapString(arg, func(s string) string { return r.re.ReplaceAllString(s, r.replacement) })
+}
+
+// _json_parse parse the JSON string, the fallback strategy when the string is not valid JSON:
+// "error" (default) returns the error, "raw" returns the raw string,
+// "xml" or "html" attempts to convert the XML/HTML to JSON object.
+type _json_parse struct{ fallback string }
+
+func new_json_parse(args ...Executor) (Executor, error) {
+	if len(args) == 0 {
+		return _json_parse{}, nil
+	}
+	switch fallback := ExecToString(args[0]); fallback {
+	case "", "error":
+		return _json_parse{}, nil
+	case "raw", "xml", "html":
+		return _json_parse{fallback}, nil
+	default:
+		return nil, fmt.Errorf("unknown json.parse fallback %s", fallback)
+	}
+}
+
+func (p _json_parse) Exec(_ context.Context, v any) (any, error) {
 	s, err := cast.ToStringE(v)
 	if err != nil {
 		return nil, err
 	}
 	var ret any
 	err = json.Unmarshal([]byte(s), &ret)
-	return ret, err
+	if err == nil {
+		return ret, nil
+	}
+	switch p.fallback {
+	case "raw":
+		return s, nil
+	case "xml", "html":
+		if ret, xmlErr := xmlToJSON(s); xmlErr == nil {
+			return ret, nil
+		}
+		return nil, err
+	default:
+		return nil, err
+	}
 }
 
 type _json_string struct{}
@@ -473,3 +1087,42 @@ func (_json_string) Exec(_ context.Context, v any) (any, error) {
 	}
 	return string(data), nil
 }
+
+// _html_unescape unescapes the HTML entities like "&quot;" to `"`.
+// When the JSON embedded in HTML is entity-encoded, it must be unescaped
+// before the json.parse, unescape after parsing will corrupt the string values:
+//
+//	$gq: div -> attr(data-json)
+//	$html.unescape:
+//	$json.parse:
+//
+// Note that the HTML parser already decoded the attribute value once,
+// so it is only necessary for the double-encoded (e.g. "&amp;quot;") or raw text.
+type _html_unescape struct{}
+
+func new_html_unescape(_ ...Executor) (Executor, error) { return _html_unescape{}, nil }
+
+func (_html_unescape) Exec(_ context.Context, arg any) (any, error) {
+	switch s := arg.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return html.UnescapeString(s), nil
+	case Iterator:
+		ret := make([]string, 0, s.Len())
+		for i := 0; i < s.Len(); i++ {
+			str, err := cast.ToStringE(s.At(i))
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, html.UnescapeString(str))
+		}
+		return NewIterator(ret), nil
+	default:
+		str, err := cast.ToStringE(arg)
+		if err != nil {
+			return nil, err
+		}
+		return html.UnescapeString(str), nil
+	}
+}
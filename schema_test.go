@@ -46,6 +46,18 @@ func TestExecutor(t *testing.T) {
 		{_pipe{_each{_inc{}}, _each{_inc{}}}, _iter[any]{1, 2, 3}, _iter[any]{3, 4, 5}},
 		{_each{_map{_raw{"k"}, _inc{}}}, _iter[any]{1}, _iter[any]{map[string]any{"k": 2}}},
 		{_map{_raw{"k"}, _json_parse{}}, `{"foo": "bar"}`, map[string]any{"k": map[string]any{"foo": "bar"}}},
+		{_pipe{_html_unescape{}, _json_parse{}}, `{&quot;foo&quot;: &quot;&lt;b&gt;&quot;}`, map[string]any{"foo": "<b>"}},
+		{_html_unescape{}, _iter[any]{"&amp;", "&#39;"}, _iter[string]{"&", "'"}},
+		{_list{}, "1", _iter[any]{"1"}},
+		{_list{}, nil, _iter[any]{}},
+		{_list{}, _iter[any]{"1", "2"}, _iter[any]{"1", "2"}},
+		{_pipe{_list{}, _each{_inc{}}}, 1, _iter[any]{2}},
+		{_pipe{_each{_map{_raw{"k"}, _inc{}}}, _index("_index")}, _iter[any]{1, 2, 3}, _iter[any]{
+			map[string]any{"k": 2, "_index": 0},
+			map[string]any{"k": 3, "_index": 1},
+			map[string]any{"k": 4, "_index": 2},
+		}},
+		{_index("i"), _iter[any]{map[string]any{}, "foo"}, _iter[any]{map[string]any{"i": 0}, "foo"}},
 	}
 	for i, c := range testCases {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
@@ -57,6 +69,383 @@ func TestExecutor(t *testing.T) {
 	}
 }
 
+func TestKeys(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arg := map[string]any{
+		"userName":  "foo",
+		"Home Page": map[string]any{"URLPath": "/"},
+		"tags":      _iter[any]{map[string]any{"tag-name": "bar"}},
+	}
+	testCases := []struct {
+		casing string
+		want   any
+	}{
+		{"lower", map[string]any{
+			"username":  "foo",
+			"home page": map[string]any{"urlpath": "/"},
+			"tags":      _iter[any]{map[string]any{"tag-name": "bar"}},
+		}},
+		{"upper", map[string]any{
+			"USERNAME":  "foo",
+			"HOME PAGE": map[string]any{"URLPATH": "/"},
+			"TAGS":      _iter[any]{map[string]any{"TAG-NAME": "bar"}},
+		}},
+		{"snake", map[string]any{
+			"user_name": "foo",
+			"home_page": map[string]any{"url_path": "/"},
+			"tags":      _iter[any]{map[string]any{"tag_name": "bar"}},
+		}},
+	}
+	for _, c := range testCases {
+		t.Run(c.casing, func(t *testing.T) {
+			exec, err := new_keys()(String(c.casing))
+			if assert.NoError(t, err) {
+				v, err := exec.Exec(ctx, arg)
+				if assert.NoError(t, err) {
+					assert.Equal(t, c.want, v)
+				}
+			}
+		})
+	}
+	_, err := new_keys()(String("camel"))
+	assert.ErrorContains(t, err, "unknown keys casing camel")
+}
+
+func TestStringStrip(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testCases := []struct {
+		schema string
+		arg    any
+		want   any
+	}{
+		{`
+$string.strip_prefix: "Price: $"
+$string.strip_suffix: " USD"
+$kind: float64`, "Price: $5.5 USD", 5.5},
+		{`
+$string.replace: [ '[^\d.]+', '' ]
+$kind: int64`, "1,299 items", int64(1299)},
+		{`$string.replace: [ '(\w+)@(\w+)', '$2:$1' ]`, "foo@bar", "bar:foo"},
+		{`$string.strip_prefix: "#"`, []string{"#a", "b", "#c"}, []string{"a", "b", "c"}},
+		{`$string.strip_suffix: "px"`, _iter[any]{"10px", 20}, NewIterator([]string{"10", "20"})},
+		{`$string.trim:`, " \n\tfoo bar\u00a0\n", "foo bar"},
+		{`$string.trim: "*-"`, "*-foo-*", "foo"},
+		{`$string.trim:`, _iter[any]{" a ", "\nb\n"}, NewIterator([]string{"a", "b"})},
+		{`$string.collapse:`, "  foo \n\n  bar\t baz ", "foo bar baz"},
+		{`$string.collapse:`, []string{" a\n b ", "c  d"}, []string{"a b", "c d"}},
+		{`$string.collapse:`, _iter[any]{"\n", " x  y "}, NewIterator([]string{"", "x y"})},
+	}
+	for i, c := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			exec, err := Compile(c.schema)
+			if !assert.NoError(t, err) {
+				return
+			}
+			v, err := exec.Exec(ctx, c.arg)
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.want, v)
+			}
+		})
+	}
+	_, err := Compile(`$string.replace: [ '(' ]`)
+	assert.ErrorContains(t, err, "missing closing )")
+}
+
+func TestJSONParseFallback(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	const xmlStr = `<user id="1"><name>foo</name><tag>a</tag><tag>b</tag><bio lang="en">hi &amp; bye</bio></user>`
+	testCases := []struct {
+		fallback string
+		arg      string
+		want     any
+		err      string
+	}{
+		{"", `{"a": 1}`, map[string]any{"a": float64(1)}, ""},
+		{"", "not json", nil, "invalid character"},
+		{"error", "not json", nil, "invalid character"},
+		{"raw", "not json", "not json", ""},
+		{"xml", xmlStr, map[string]any{"user": map[string]any{
+			"@id":  "1",
+			"name": "foo",
+			"tag":  []any{"a", "b"},
+			"bio":  map[string]any{"@lang": "en", "#text": "hi & bye"},
+		}}, ""},
+		{"html", `<ul><li>1</li><li>2&nbsp;</li><br></ul>`, map[string]any{"ul": map[string]any{"li": []any{"1", "2"}, "br": ""}}, ""},
+		{"xml", "not xml", nil, "invalid character"},
+	}
+	for i, c := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			exec, err := new_json_parse(String(c.fallback))
+			if !assert.NoError(t, err) {
+				return
+			}
+			v, err := exec.Exec(ctx, c.arg)
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.want, v)
+			}
+		})
+	}
+	_, err := new_json_parse(String("yaml"))
+	assert.ErrorContains(t, err, "unknown json.parse fallback yaml")
+}
+
+func TestDefaultRequired(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("default", func(t *testing.T) {
+		exec, err := Compile(`
+$map:
+  title:
+    $default:
+      - $debug:
+      - untitled
+  count:
+    $default:
+      - $debug:
+      - 0
+    $kind: int
+  owner:
+    $default:
+      - $debug:
+      - $map:
+          name: anonymous`)
+		if !assert.NoError(t, err) {
+			return
+		}
+		v, err := exec.Exec(ctx, "")
+		if assert.NoError(t, err) {
+			assert.Equal(t, map[string]any{
+				"title": "untitled",
+				"count": int32(0),
+				"owner": map[string]any{"name": "anonymous"},
+			}, v)
+		}
+		v, err = exec.Exec(ctx, "1")
+		if assert.NoError(t, err) {
+			assert.Equal(t, map[string]any{"title": "1", "count": int32(1), "owner": "1"}, v)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		for i, arg := range []any{nil, "", _iter[any]{}, []any{}, []string{}, map[string]any{}} {
+			v, err := _default{_debug(""), String("d")}.Exec(ctx, arg)
+			if assert.NoError(t, err, i) {
+				assert.Equal(t, "d", v, i)
+			}
+		}
+	})
+
+	t.Run("required", func(t *testing.T) {
+		exec := _map{
+			String("id"), _required{_raw{nil}},
+			String("name"), _raw{"foo"},
+			String("user"), _map{String("email"), _required{_raw{""}}},
+		}
+		v, err := exec.Exec(ctx, nil)
+		assert.ErrorIs(t, err, ErrRequired)
+		assert.ErrorContains(t, err, "id: required value is empty")
+		assert.ErrorContains(t, err, "user: email: required value is empty")
+		assert.Equal(t, map[string]any{
+			"id":   nil,
+			"name": "foo",
+			"user": map[string]any{"email": ""},
+		}, v)
+
+		v, err = _each{_map{String("id"), _required{_debug("")}}}.Exec(ctx, _iter[any]{"1", nil})
+		assert.ErrorContains(t, err, "1: id: required value is empty")
+		assert.Equal(t, _iter[any]{map[string]any{"id": "1"}, map[string]any{"id": nil}}, v)
+
+		v, err = _default{_required{_raw{nil}}, String("d")}.Exec(ctx, nil)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "d", v)
+		}
+
+		pipe, err := Compile(`
+$list:
+$each:
+  $map:
+    id:
+      $required:
+        $debug:
+$slice: 2`)
+		if assert.NoError(t, err) {
+			v, err = pipe.Exec(ctx, []any{"1", nil, "3"})
+			assert.ErrorContains(t, err, "1: id: required value is empty")
+			assert.Equal(t, _iter[any]{map[string]any{"id": "1"}, map[string]any{"id": nil}}, v)
+		}
+	})
+
+	_, err := new_default(String("a"))
+	assert.ErrorContains(t, err, "default needs 2 parameters")
+	_, err = new_required()
+	assert.ErrorContains(t, err, "required needs 1 parameter")
+}
+
+func TestSlice(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	rows := _iter[any]{"header", "a", "b", "c", "d"}
+	testCases := []struct {
+		str  string
+		arg  any
+		want any
+	}{
+		{`$slice: 2`, rows, _iter[any]{"header", "a"}},
+		{`$slice: -2`, rows, _iter[any]{"c", "d"}},
+		{`$slice: 10`, rows, rows},
+		{`$slice: {offset: 1}`, rows, _iter[any]{"a", "b", "c", "d"}},
+		{`$slice: {offset: 10}`, rows, _iter[any]{}},
+		{`$slice: {offset: 1, limit: 2}`, rows, _iter[any]{"a", "b"}},
+		{`$slice: {offset: 1, limit: -3}`, rows, _iter[any]{"b", "c", "d"}},
+		{`$slice: {offset: 3, limit: -3}`, rows, _iter[any]{"c", "d"}},
+		{`$slice: {offset: 1, limit: 1}`, []string{"x", "y"}, _iter[any]{"y"}},
+		{`$slice: 1`, nil, _iter[any]{}},
+		{`$slice: 1`, "x", _iter[any]{"x"}},
+	}
+	for i, c := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			exec, err := Compile(c.str)
+			if !assert.NoError(t, err) {
+				return
+			}
+			v, err := exec.Exec(ctx, c.arg)
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.want, v)
+			}
+		})
+	}
+
+	exec, err := Compile(`
+$slice:
+  offset: 1
+  limit: 2
+$each:
+  $string.trim:`)
+	if assert.NoError(t, err) {
+		v, err := exec.Exec(ctx, _iter[any]{" name ", " a ", " b ", " c "})
+		if assert.NoError(t, err) {
+			assert.Equal(t, _iter[any]{"a", "b"}, v)
+		}
+	}
+
+	for str, msg := range map[string]string{
+		`$slice: x`:            "invalid slice limit x",
+		`$slice: {offset: -1}`: "slice offset -1 must not be negative",
+		`$slice: {offset: x}`:  "invalid slice offset x",
+		`$slice: {step: 1}`:    "unknown slice option step",
+		`$slice: [1, 2, 3]`:    "slice needs the limit or the offset and limit mapping",
+	} {
+		_, err = Compile(str)
+		assert.ErrorContains(t, err, msg, str)
+	}
+}
+
+func TestUnique(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	exec, err := Compile(`
+$list:
+$unique:`)
+	if assert.NoError(t, err) {
+		v, err := exec.Exec(ctx, []string{"go", "js", "go", "rust", "js"})
+		if assert.NoError(t, err) {
+			assert.Equal(t, _iter[any]{"go", "js", "rust"}, v)
+		}
+		v, err = exec.Exec(ctx, _iter[any]{1, "1", 1, []any{"a"}, []any{"a"}, nil, nil})
+		if assert.NoError(t, err) {
+			assert.Equal(t, _iter[any]{1, "1", []any{"a"}, nil}, v)
+		}
+		v, err = exec.Exec(ctx, nil)
+		if assert.NoError(t, err) {
+			assert.Equal(t, _iter[any]{}, v)
+		}
+	}
+
+	exec, err = Compile(`
+$each:
+  $map:
+    id:
+      $string.trim:
+$unique: id`)
+	if assert.NoError(t, err) {
+		v, err := exec.Exec(ctx, _iter[any]{"1", " 2", "1 ", "3", "2"})
+		if assert.NoError(t, err) {
+			assert.Equal(t, _iter[any]{
+				map[string]any{"id": "1"},
+				map[string]any{"id": "2"},
+				map[string]any{"id": "3"},
+			}, v)
+		}
+	}
+
+	v, err := _unique("id").Exec(ctx, _iter[any]{
+		map[string]any{"id": 1, "name": "a"},
+		map[string]any{"name": "b"},
+		map[string]any{"id": 1, "name": "c"},
+		map[string]any{"name": "b"},
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, _iter[any]{
+			map[string]any{"id": 1, "name": "a"},
+			map[string]any{"name": "b"},
+			map[string]any{"name": "b"},
+		}, v)
+	}
+	_, err = _unique("id").Exec(ctx, _iter[any]{"1"})
+	assert.ErrorContains(t, err, "unique by id unexpected type string")
+}
+
+func TestFirst(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	exec, err := Compile(`
+$first:
+  - - $json.parse:
+    - $kind: string
+  - - $string.trim: "<>"
+    - $string.collapse:
+  - fallback`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	v, err := exec.Exec(ctx, `"layout a"`)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "layout a", v)
+	}
+	v, err = exec.Exec(ctx, "<layout   b>")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "layout b", v)
+	}
+	v, err = exec.Exec(ctx, "<>")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "fallback", v)
+	}
+
+	v, err = _first{_raw{""}, _raw{_iter[any]{}}}.Exec(ctx, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, _iter[any]{}, v)
+	}
+	v, err = _first{errexec{}, _raw{nil}}.Exec(ctx, nil)
+	if assert.NoError(t, err) {
+		assert.Nil(t, v)
+	}
+	_, err = _first{errexec{}, errexec{}}.Exec(ctx, nil)
+	assert.ErrorContains(t, err, "some error")
+
+	_, err = new_first()
+	assert.ErrorContains(t, err, "first needs at least 1 parameter")
+}
+
 func TestDebug(t *testing.T) {
 	data := new(bytes.Buffer)
 	ctx := WithLogger(context.Background(), slog.New(slog.NewTextHandler(data, &slog.HandlerOptions{Level: slog.LevelDebug})))
@@ -182,6 +571,34 @@ $map:
 		}
 	})
 
+	t.Run("list", func(t *testing.T) {
+		expect := _map{String("tags"), _pipe{_debug("the tag"), _list{}}}
+		exec, err := Compile(`
+$map:
+  tags:
+    $debug: the tag
+    $list:`)
+		if assert.NoError(t, err) {
+			assert.True(t, deepEqual(expect, exec))
+			v, err := exec.Exec(context.Background(), "foo")
+			if assert.NoError(t, err) {
+				assert.Equal(t, map[string]any{"tags": _iter[any]{"foo"}}, v)
+			}
+		}
+	})
+
+	t.Run("index", func(t *testing.T) {
+		exec, err := Compile(`
+$each:
+  $map:
+    v:
+      $kind: int
+$index:`)
+		if assert.NoError(t, err) {
+			assert.True(t, deepEqual(_pipe{_each{_map{String("v"), KindInt}}, _index("_index")}, exec))
+		}
+	})
+
 	t.Run("pipe", func(t *testing.T) {
 		expect := _map{String("size"), _pipe{_debug("the size"), KindInt}}
 		exec, err := Compile(`
@@ -195,3 +612,36 @@ $map:
 		}
 	})
 }
+
+func TestCompileSyntax(t *testing.T) {
+	t.Parallel()
+	yamlExec, err := Compile(`
+$map:
+  title:
+    $template: ${name}
+  tags:
+    - $slice: 2
+    - $list:`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	jsonExec, err := Compile(`{"$map": {"title": {"$template": "${name}"}, "tags": [{"$slice": 2}, {"$list": null}]}}`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, deepEqual(yamlExec, jsonExec))
+
+	v, err := yamlExec.Exec(context.Background(), map[string]any{"name": "foo"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "foo", v.(map[string]any)["title"])
+	}
+
+	_, err = Compile("$map:\n  title: foo\n   tags: bar")
+	assert.EqualError(t, err, `yaml: line 3: mapping values are not allowed in this context, near "tags: bar"`)
+
+	_, err = Compile("$map:\n\ttitle: foo")
+	assert.ErrorContains(t, err, `found character that cannot start any token`)
+
+	err = Validate("$map: [foo")
+	assert.ErrorContains(t, err, `near "$map: [foo"`)
+}
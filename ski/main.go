@@ -20,6 +20,8 @@ import (
 	_ "github.com/shiroyk/ski/js/modules/crypto"
 	_ "github.com/shiroyk/ski/js/modules/encoding"
 	_ "github.com/shiroyk/ski/js/modules/http"
+	_ "github.com/shiroyk/ski/js/modules/querystring"
+	_ "github.com/shiroyk/ski/js/modules/util"
 
 	_ "github.com/shiroyk/ski/gq"
 	_ "github.com/shiroyk/ski/jq"
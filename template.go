@@ -0,0 +1,124 @@
+package ski
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// _template interpolates the `${path}` with the value of the dot separated path
+// on the arg, the path segment is the object key or the Iterator index, the empty
+// path `${}` is the arg itself. The missing value is interpolated as empty string.
+// Compose the fields by the map then interpolate them. eg:
+//
+//	$map:
+//	  base:
+//	    $css: base -> attr(href)
+//	  id:
+//	    $css: .item -> attr(data-id)
+//	$template: ${base}/items/${id}
+//
+// The path starts with `$` looks up the value of the string key on the context,
+// which is seeded by the caller with the Context.SetValue before the execution. eg:
+//
+//	$template: ${$baseURL}/items?token=${$auth.token}
+type _template []templatePart
+
+type templatePart struct {
+	text string
+	path []string // nil if the part is the text
+}
+
+func new_template(args ...Executor) (Executor, error) {
+	if len(args) != 1 {
+		return nil, errors.New("template needs 1 parameter")
+	}
+	str := ExecToString(args[0])
+	var t _template
+	for {
+		start := strings.Index(str, "${")
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(str[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("template unclosed ${ at %d", start)
+		}
+		if start > 0 {
+			t = append(t, templatePart{text: str[:start]})
+		}
+		path := []string{}
+		if p := strings.TrimSpace(str[start+2 : start+end]); p != "" {
+			path = strings.Split(p, ".")
+		}
+		t = append(t, templatePart{path: path})
+		str = str[start+end+1:]
+	}
+	if str != "" {
+		t = append(t, templatePart{text: str})
+	}
+	return t, nil
+}
+
+func (t _template) Exec(ctx context.Context, arg any) (any, error) {
+	var buf strings.Builder
+	for _, part := range t {
+		if part.path == nil {
+			buf.WriteString(part.text)
+			continue
+		}
+		var v any
+		if len(part.path) > 0 && strings.HasPrefix(part.path[0], "$") {
+			v = lookup(ctx.Value(part.path[0][1:]), part.path[1:])
+		} else {
+			v = lookup(arg, part.path)
+		}
+		s, err := templateString(v)
+		if err != nil {
+			return nil, fmt.Errorf("template ${%s}: %w", strings.Join(part.path, "."), err)
+		}
+		buf.WriteString(s)
+	}
+	return buf.String(), nil
+}
+
+// lookup returns the value of the path, nil if not found
+func lookup(v any, path []string) any {
+	for _, key := range path {
+		switch t := v.(type) {
+		case map[string]any:
+			v = t[key]
+		case Iterator:
+			i, err := strconv.Atoi(key)
+			if err != nil {
+				return nil
+			}
+			v = t.At(i)
+		case []any:
+			v = lookup(NewIterator(t), []string{key})
+		case []string:
+			v = lookup(NewIterator(t), []string{key})
+		default:
+			return nil
+		}
+	}
+	return v
+}
+
+// templateString converts the value to string, the object and array are JSON encoded.
+func templateString(v any) (string, error) {
+	switch v.(type) {
+	case nil:
+		return "", nil
+	case map[string]any, Iterator, []any, []string:
+		data, err := json.Marshal(v)
+		return string(data), err
+	default:
+		return cast.ToStringE(v)
+	}
+}
@@ -0,0 +1,87 @@
+package ski
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplate(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	exec, err := Compile(`
+$map:
+  base:
+    $string.replace: ["/[^/]*$", ""]
+  id:
+    $string.replace: ["^.*/", ""]
+$template: ${base}/items/${id}?ref=${ missing }`)
+	if assert.NoError(t, err) {
+		v, err := exec.Exec(ctx, "https://example.com/114")
+		if assert.NoError(t, err) {
+			assert.Equal(t, "https://example.com/items/114?ref=", v)
+		}
+	}
+
+	testCases := []struct {
+		str  string
+		arg  any
+		want string
+	}{
+		{`raw: ${}`, "content", "raw: content"},
+		{`${a.b}-${a.c.1}-${d.0}`, map[string]any{
+			"a": map[string]any{"b": 1, "c": NewIterator([]string{"x", "y"})},
+			"d": []any{true},
+		}, "1-y-true"},
+		{`${a}`, map[string]any{"a": map[string]any{"b": []string{"c"}}}, `{"b":["c"]}`},
+		{`${0.name}`, NewIterator([]any{map[string]any{"name": "foo"}}), "foo"},
+		{`${a.x}${b}`, map[string]any{"a": "str"}, ""},
+		{`no placeholder`, nil, "no placeholder"},
+	}
+	for _, c := range testCases {
+		exec, err := new_template(String(c.str))
+		if !assert.NoError(t, err, c.str) {
+			continue
+		}
+		v, err := exec.Exec(ctx, c.arg)
+		if assert.NoError(t, err, c.str) {
+			assert.Equal(t, c.want, v, c.str)
+		}
+	}
+
+	_, err = new_template(String("${a"))
+	assert.ErrorContains(t, err, "template unclosed ${ at 0")
+	_, err = new_template()
+	assert.ErrorContains(t, err, "template needs 1 parameter")
+}
+
+func TestTemplateContext(t *testing.T) {
+	t.Parallel()
+	ctx := NewContext(context.Background(), map[any]any{"baseURL": "https://example.com"})
+	ctx.SetValue("auth", map[string]any{"token": "t0ken"})
+
+	exec, err := Compile(`
+$map:
+  a:
+    $template: ${$baseURL}/a/${}?token=${$auth.token}
+  b:
+    $template: ${$baseURL}/b/${}${$missing}`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	v, err := exec.Exec(WithConcurrency(ctx, 2), "1")
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]any{
+			"a": "https://example.com/a/1?token=t0ken",
+			"b": "https://example.com/b/1",
+		}, v)
+	}
+
+	ctx.SetValue("baseURL", "https://example.org")
+	v, err = exec.Exec(ctx, "2")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "https://example.org/a/2?token=t0ken", v.(map[string]any)["a"])
+	}
+}
@@ -0,0 +1,100 @@
+package ski
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timing the durations of the request phases, the zero duration means
+// the phase did not happen, eg: the idle connection is reused.
+// Only the phases of the last redirect are recorded.
+type Timing struct {
+	// DNS the duration of the DNS lookup
+	DNS time.Duration `json:"dns"`
+	// Connect the duration of the TCP connection
+	Connect time.Duration `json:"connect"`
+	// TLS the duration of the TLS handshake
+	TLS time.Duration `json:"tls"`
+	// TTFB the duration from the request start to the first response byte
+	TTFB time.Duration `json:"ttfb"`
+	// Total the duration from the request start to the body is read completely or closed
+	Total time.Duration `json:"total"`
+}
+
+// timing collects the Timing by the httptrace.ClientTrace
+type timing struct {
+	mu                                      sync.Mutex
+	start, dnsStart, connectStart, tlsStart time.Time
+	Timing
+}
+
+var timingKey byte
+
+// withTiming returns a copy of the request which collects the Timing
+func withTiming(req *http.Request) (*http.Request, *timing) {
+	t := &timing{start: time.Now()}
+	ctx := context.WithValue(req.Context(), &timingKey, t)
+	return req.WithContext(httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { t.begin(&t.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { t.end(&t.DNS, &t.dnsStart) },
+		ConnectStart:         func(string, string) { t.begin(&t.connectStart) },
+		ConnectDone:          func(string, string, error) { t.end(&t.Connect, &t.connectStart) },
+		TLSHandshakeStart:    func() { t.begin(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { t.end(&t.TLS, &t.tlsStart) },
+		GotFirstResponseByte: func() { t.end(&t.TTFB, &t.start) },
+	})), t
+}
+
+func (t *timing) begin(start *time.Time) {
+	t.mu.Lock()
+	*start = time.Now()
+	t.mu.Unlock()
+}
+
+func (t *timing) end(d *time.Duration, start *time.Time) {
+	t.mu.Lock()
+	*d = time.Since(*start)
+	t.mu.Unlock()
+}
+
+// timingBody records the Total duration when the body is read completely or closed
+type timingBody struct {
+	io.ReadCloser
+	*timing
+	once sync.Once
+}
+
+func (b *timingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if errors.Is(err, io.EOF) {
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *timingBody) Close() error {
+	b.finish()
+	return b.ReadCloser.Close()
+}
+
+func (b *timingBody) finish() { b.once.Do(func() { b.end(&b.Total, &b.start) }) }
+
+// TimingFromResponse returns the Timing of the response, ok is false if the FetchOptions.Timing is disabled.
+func TimingFromResponse(res *http.Response) (Timing, bool) {
+	if res == nil || res.Request == nil {
+		return Timing{}, false
+	}
+	t, ok := res.Request.Context().Value(&timingKey).(*timing)
+	if !ok {
+		return Timing{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.Timing, true
+}
@@ -0,0 +1,68 @@
+package ski
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetcherTiming(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		w.(http.Flusher).Flush()
+		time.Sleep(10 * time.Millisecond)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	// use the localhost to resolve the host by DNS
+	url := strings.Replace(ts.URL, "127.0.0.1", "localhost", 1)
+	transport := ts.Client().Transport.(*http.Transport).Clone()
+	transport.TLSClientConfig.ServerName = "example.com"
+	fetch := NewFetcher(FetchOptions{Timing: true, Transport: transport})
+
+	get := func() Timing {
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		res, err := fetch.Do(req)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		timing, ok := TimingFromResponse(res)
+		assert.True(t, ok)
+		assert.Zero(t, timing.Total)
+		_, err = io.ReadAll(res.Body)
+		assert.NoError(t, err)
+		_ = res.Body.Close()
+		timing, _ = TimingFromResponse(res)
+		return timing
+	}
+
+	first := get()
+	assert.Positive(t, first.DNS)
+	assert.Positive(t, first.Connect)
+	assert.Positive(t, first.TLS)
+	assert.GreaterOrEqual(t, first.TTFB, first.DNS+first.Connect+first.TLS)
+	assert.GreaterOrEqual(t, first.TTFB, 10*time.Millisecond)
+	assert.GreaterOrEqual(t, first.Total, first.TTFB+10*time.Millisecond)
+
+	// the idle connection is reused
+	reused := get()
+	assert.Zero(t, reused.DNS)
+	assert.Zero(t, reused.Connect)
+	assert.Zero(t, reused.TLS)
+	assert.GreaterOrEqual(t, reused.Total, reused.TTFB)
+
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	res, err := NewFetcher(FetchOptions{Transport: transport}).Do(req)
+	if assert.NoError(t, err) {
+		_ = res.Body.Close()
+		_, ok := TimingFromResponse(res)
+		assert.False(t, ok)
+	}
+}
@@ -0,0 +1,49 @@
+package ski
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/shiroyk/ski"
+
+// WithTracerProvider trace the execution of the compiled Executor,
+// a span per executor with the executor name and position.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *compiler) {
+		if tp != nil {
+			c.tracer = tp.Tracer(tracerName)
+		}
+	}
+}
+
+// _trace the Executor with span
+type _trace struct {
+	Executor
+	tracer       trace.Tracer
+	name         string
+	line, column int
+}
+
+func (t _trace) Exec(ctx context.Context, arg any) (any, error) {
+	ctx, span := t.tracer.Start(ctx, t.name, trace.WithAttributes(
+		attribute.String("ski.executor", t.name),
+		attribute.Int("ski.line", t.line),
+		attribute.Int("ski.column", t.column),
+		attribute.String("ski.arg.type", fmt.Sprintf("%T", arg)),
+	))
+	defer span.End()
+
+	ret, err := t.Executor.Exec(ctx, arg)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return ret, err
+	}
+	span.SetAttributes(attribute.String("ski.result.type", fmt.Sprintf("%T", ret)))
+	return ret, nil
+}
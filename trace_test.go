@@ -0,0 +1,80 @@
+package ski
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func spanAttrs(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestTraceExecutor(t *testing.T) {
+	t.Parallel()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	exec, err := Compile(`
+$map:
+  size:
+    $kind: int64`, WithTracerProvider(tp))
+	if !assert.NoError(t, err) {
+		return
+	}
+	v, err := exec.Exec(context.Background(), "1")
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]any{"size": int64(1)}, v)
+	}
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, "map", spans[1].Name())
+		assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+		assert.Equal(t, "kind", spans[0].Name())
+		attrs := spanAttrs(spans[0])
+		assert.Equal(t, "kind", attrs["ski.executor"].AsString())
+		assert.Equal(t, int64(4), attrs["ski.line"].AsInt64())
+		assert.Equal(t, "string", attrs["ski.arg.type"].AsString())
+		assert.Equal(t, "int64", attrs["ski.result.type"].AsString())
+	}
+}
+
+func TestTraceFetcher(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer ts.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	fetch := NewFetcher(FetchOptions{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+	res, err := fetch.Do(req)
+	if assert.NoError(t, err) {
+		_ = res.Body.Close()
+	}
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "HTTP GET", spans[0].Name())
+		attrs := spanAttrs(spans[0])
+		assert.Equal(t, http.MethodGet, attrs["http.request.method"].AsString())
+		assert.Equal(t, ts.URL, attrs["url.full"].AsString())
+		assert.Equal(t, int64(http.StatusTeapot), attrs["http.response.status_code"].AsInt64())
+		assert.True(t, spans[0].EndTime().After(spans[0].StartTime()))
+	}
+}
@@ -0,0 +1,71 @@
+package ski
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Transform the host-provided function to transform the value,
+// it is used for the transforms impossible in executors (e.g. OCR).
+type Transform func(ctx context.Context, arg any) (any, error)
+
+// RegisterTransform registers the Transform with the given name.
+// The registered Transform can be used with `$transform: name`.
+func RegisterTransform(name string, fn Transform) {
+	if name == "" {
+		panic("ski: invalid transform name")
+	}
+	if fn == nil {
+		panic("ski: Transform is nil")
+	}
+
+	transforms.Lock()
+	defer transforms.Unlock()
+	transforms.registry[name] = fn
+}
+
+// GetTransform returns a Transform with the given name
+func GetTransform(name string) (Transform, bool) {
+	transforms.RLock()
+	defer transforms.RUnlock()
+	fn, ok := transforms.registry[name]
+	return fn, ok
+}
+
+// RemoveTransform removes a Transform with the given name
+func RemoveTransform(name string) {
+	transforms.Lock()
+	defer transforms.Unlock()
+	delete(transforms.registry, name)
+}
+
+var transforms = struct {
+	sync.RWMutex
+	registry map[string]Transform
+}{
+	registry: make(map[string]Transform),
+}
+
+type _transform struct {
+	name string
+	fn   Transform
+}
+
+func new_transform() NewExecutor {
+	return StringExecutor(func(name string) (Executor, error) {
+		fn, ok := GetTransform(name)
+		if !ok {
+			return nil, fmt.Errorf("transform %s not found", name)
+		}
+		return _transform{name, fn}, nil
+	})
+}
+
+func (t _transform) Exec(ctx context.Context, arg any) (any, error) {
+	ret, err := t.fn(ctx, arg)
+	if err != nil {
+		return nil, fmt.Errorf("transform %s: %w", t.name, err)
+	}
+	return ret, nil
+}
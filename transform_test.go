@@ -0,0 +1,43 @@
+package ski
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransform(t *testing.T) {
+	t.Parallel()
+	RegisterTransform("test_upper", func(_ context.Context, arg any) (any, error) {
+		return strings.ToUpper(cast.ToString(arg)), nil
+	})
+	RegisterTransform("test_fail", func(context.Context, any) (any, error) {
+		return nil, errors.New("failed")
+	})
+	defer RemoveTransform("test_upper")
+	defer RemoveTransform("test_fail")
+
+	exec, err := Compile(`
+$map:
+  name:
+    $transform: test_upper`)
+	if assert.NoError(t, err) {
+		v, err := exec.Exec(context.Background(), "foo")
+		if assert.NoError(t, err) {
+			assert.Equal(t, map[string]any{"name": "FOO"}, v)
+		}
+	}
+
+	exec, err = Compile(`$transform: test_fail`)
+	if assert.NoError(t, err) {
+		_, err = exec.Exec(context.Background(), "foo")
+		assert.ErrorContains(t, err, "transform test_fail: failed")
+	}
+
+	_, err = Compile(`$transform: not_exists`)
+	assert.ErrorContains(t, err, "transform not_exists not found")
+}
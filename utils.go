@@ -2,9 +2,12 @@ package ski
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"reflect"
+	"strings"
 )
 
 var loggerKey byte
@@ -64,3 +67,105 @@ func MapValues[M ~map[K]V, K comparable, V any](m M) []V {
 	}
 	return r
 }
+
+// ExecInto executes the Executor and stores the result in the value pointed to by v,
+// the result is converted by JSON round-trip, so the struct fields follow the json tags.
+// The struct fields tagged with `ski:"required"` must be present in the result.
+//
+//	type Repo struct {
+//		Name  string   `json:"name" ski:"required"`
+//		Stars int      `json:"stars"`
+//		Tags  []string `json:"tags"`
+//	}
+//	var repo Repo
+//	err := ski.ExecInto(ctx, executor, content, &repo)
+func ExecInto(ctx context.Context, exec Executor, arg any, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("ExecInto requires non-nil pointer, but got %T", v)
+	}
+	ret, err := exec.Exec(ctx, arg)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(ret)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return err
+	}
+	var raw any
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	return checkRequired(rv.Type().Elem(), raw, "")
+}
+
+// checkRequired checks the struct fields tagged with `ski:"required"` are present
+func checkRequired(t reflect.Type, raw any, path string) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, _ := raw.(map[string]any)
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag, tagged := field.Tag.Lookup("json")
+			if tag == "-" {
+				continue
+			}
+			name, _, _ := strings.Cut(tag, ",")
+			if field.Anonymous && name == "" {
+				// the embedded struct fields are flattened as the encoding/json
+				ft := field.Type
+				if ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				if ft.Kind() == reflect.Struct {
+					if err := checkRequired(ft, raw, path); err != nil {
+						return err
+					}
+					continue
+				}
+			}
+			if !field.IsExported() {
+				continue
+			}
+			if !tagged || name == "" {
+				name = field.Name
+			}
+			value, ok := lookupKey(obj, name)
+			if (!ok || value == nil) && field.Tag.Get("ski") == "required" {
+				return fmt.Errorf("required field %s%s is missing", path, name)
+			}
+			if err := checkRequired(field.Type, value, path+name+"."); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		items, _ := raw.([]any)
+		for i, item := range items {
+			if err := checkRequired(t.Elem(), item, fmt.Sprintf("%s%d.", path, i)); err != nil {
+				return err
+			}
+		}
+	default:
+	}
+	return nil
+}
+
+// lookupKey returns the value of the key, the key is matched case-insensitively
+// if no exact match as the encoding/json.
+func lookupKey(obj map[string]any, key string) (any, bool) {
+	if v, ok := obj[key]; ok {
+		return v, true
+	}
+	for k, v := range obj {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return nil, false
+}
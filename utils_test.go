@@ -0,0 +1,96 @@
+package ski
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecInto(t *testing.T) {
+	t.Parallel()
+	type Tag struct {
+		Name string `json:"name" ski:"required"`
+	}
+	type Repo struct {
+		Name  string   `json:"name" ski:"required"`
+		Stars int64    `json:"stars"`
+		Tags  []Tag    `json:"tags"`
+		Langs []string `json:"langs"`
+	}
+
+	exec, err := Compile(`
+$map:
+  name: ski
+  stars:
+    $kind: int64
+  tags:
+    $list:
+    $each:
+      $map:
+        name:
+          $debug:
+  langs:
+    $list:`)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var repo Repo
+	if assert.NoError(t, ExecInto(context.Background(), exec, "1024", &repo)) {
+		assert.Equal(t, Repo{
+			Name:  "ski",
+			Stars: 1024,
+			Tags:  []Tag{{Name: "1024"}},
+			Langs: []string{"1024"},
+		}, repo)
+	}
+
+	exec, err = Compile(`
+$map:
+  langs:
+    $list:`)
+	if assert.NoError(t, err) {
+		err = ExecInto(context.Background(), exec, "go", &repo)
+		assert.ErrorContains(t, err, "required field name is missing")
+	}
+
+	exec, err = Compile(`
+$map:
+  name: ski
+  tags:
+    $list:
+    $each:
+      $map:
+        name:
+          $json.parse:`)
+	if assert.NoError(t, err) {
+		err = ExecInto(context.Background(), exec, "invalid", &Repo{})
+		assert.ErrorContains(t, err, "required field tags.0.name is missing")
+	}
+
+	assert.ErrorContains(t, ExecInto(context.Background(), exec, nil, repo), "requires non-nil pointer")
+
+	t.Run("case-insensitive", func(t *testing.T) {
+		var tag Tag
+		if assert.NoError(t, ExecInto(context.Background(), Raw(map[string]any{"Name": "go"}), nil, &tag)) {
+			assert.Equal(t, Tag{Name: "go"}, tag)
+		}
+	})
+
+	t.Run("embedded", func(t *testing.T) {
+		type Meta struct {
+			ID string `json:"id" ski:"required"`
+		}
+		type Item struct {
+			Meta
+			Title string `json:"title"`
+		}
+		var item Item
+		if assert.NoError(t, ExecInto(context.Background(), Raw(map[string]any{"id": "1", "title": "ski"}), nil, &item)) {
+			assert.Equal(t, Item{Meta{ID: "1"}, "ski"}, item)
+		}
+		err := ExecInto(context.Background(), Raw(map[string]any{"title": "ski"}), nil, &Item{})
+		assert.ErrorContains(t, err, "required field id is missing")
+	})
+}
@@ -0,0 +1,182 @@
+package ski
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaError the problem of the schema, the Path is the dot separated mapping keys
+// and sequence indexes of the YAML node. eg: "$map.items.$each.0.$css"
+type SchemaError struct {
+	Path   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("%s line %d column %d: %s", e.Path, e.Line, e.Column, e.Err)
+}
+
+func (e *SchemaError) Unwrap() error { return e.Err }
+
+// ValidateError the problems of the invalid schema in the document order
+type ValidateError []*SchemaError
+
+func (e ValidateError) Error() string {
+	msg := make([]string, len(e))
+	for i, err := range e {
+		msg[i] = err.Error()
+	}
+	return fmt.Sprintf("invalid schema: %s", strings.Join(msg, "; "))
+}
+
+func (e ValidateError) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Validate checks the schema with the Option up front, unlike the Compile stops
+// at the first error, returns the ValidateError listing every problem: the unknown
+// executor, the executor invalid arguments, the empty mapping, the mapping mixes
+// the executors and the fields, and the invalid reference.
+func Validate(str string, opts ...Option) error {
+	c := new(compiler)
+	for _, opt := range opts {
+		opt(c)
+	}
+	if len(c.overrides) > 0 {
+		var err error
+		if str, err = Merge(str, c.overrides...); err != nil {
+			return err
+		}
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(str), &node); err != nil {
+		return syntaxError(str, err)
+	}
+	root := documentContent(&node)
+	if root == nil || root.Kind == 0 {
+		return nil
+	}
+	v := validator{c: *c}
+	v.node(root, nil)
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+	return nil
+}
+
+type validator struct {
+	c    compiler
+	errs ValidateError
+}
+
+func (v *validator) add(path []string, node *yaml.Node, err error) {
+	v.errs = append(v.errs, &SchemaError{
+		Path:   strings.Join(path, "."),
+		Line:   node.Line,
+		Column: node.Column,
+		Err:    err,
+	})
+}
+
+// node validates the node recursively, returns false if any problem found
+func (v *validator) node(node *yaml.Node, path []string) bool {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return true
+	case yaml.AliasNode:
+		return v.node(node.Alias, path)
+	case yaml.SequenceNode:
+		ok := true
+		for i, item := range node.Content {
+			ok = v.node(item, append(slices.Clip(path), strconv.Itoa(i))) && ok
+		}
+		return ok
+	case yaml.MappingNode:
+		if len(node.Content) == 0 {
+			v.add(path, node, errors.New("empty mapping"))
+			return false
+		}
+		if strings.HasPrefix(node.Content[0].Value, "$") {
+			return v.executors(node, path)
+		}
+		return v.fields(node, path)
+	default:
+		v.add(path, node, errors.New("invalid node type"))
+		return false
+	}
+}
+
+// executors validates the mapping of the executors, the key "$" prefix is optional
+func (v *validator) executors(node *yaml.Node, path []string) bool {
+	ok := true
+	for i := 0; i < len(node.Content); i += 2 {
+		ok = v.executor(node.Content[i], node.Content[i+1], path) && ok
+	}
+	return ok
+}
+
+// fields validates the mapping of the fields, the mapping value is the executors
+func (v *validator) fields(node *yaml.Node, path []string) bool {
+	ok := true
+	for i := 0; i < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		field := append(slices.Clip(path), key.Value)
+		if strings.HasPrefix(key.Value, "$") {
+			v.add(field, key, errors.New("mapping mixes the executors and the fields"))
+			ok = false
+			continue
+		}
+		if value.Kind != yaml.MappingNode {
+			ok = v.node(value, field) && ok
+			continue
+		}
+		if len(value.Content) == 0 {
+			v.add(field, value, errors.New("empty mapping"))
+			ok = false
+			continue
+		}
+		ok = v.executors(value, field) && ok
+	}
+	return ok
+}
+
+// executor validates the executor name and arguments
+func (v *validator) executor(k, value *yaml.Node, path []string) bool {
+	path = append(slices.Clip(path), k.Value)
+	name := strings.TrimPrefix(k.Value, "$")
+	if name == "ref" {
+		if _, err := v.c.compileRef(k, value); err != nil {
+			v.add(path, k, err)
+			return false
+		}
+		return true
+	}
+	init, ok := GetExecutor(name)
+	if !ok {
+		v.add(path, k, fmt.Errorf("executor %s not found", name))
+		return false
+	}
+	if !v.node(value, path) {
+		return false
+	}
+	args, err := v.c.compileNode(value)
+	if err == nil {
+		_, err = init(args...)
+	}
+	if err != nil {
+		v.add(path, k, err)
+		return false
+	}
+	return true
+}
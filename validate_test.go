@@ -0,0 +1,90 @@
+package ski
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+	assert.NoError(t, Validate(`
+$map:
+  title:
+    $template: ${name}
+  tags:
+    - $slice: 2
+    - $unique:
+  author:
+    $ref: author`, WithDefinition("author", `$string.trim:`)))
+	assert.NoError(t, Validate(``))
+
+	testCases := []struct {
+		schema   string
+		problems []SchemaError
+	}{
+		{
+			`$unknown: foo`,
+			[]SchemaError{{Path: "$unknown", Line: 1, Column: 1, Err: errors.New("executor unknown not found")}},
+		},
+		{
+			`
+$map:
+  title:
+    $css: h1
+  name:
+    $template: [a, b]
+  $each: foo`,
+			[]SchemaError{
+				{Path: "$map.title.$css", Line: 4, Column: 5, Err: errors.New("executor css not found")},
+				{Path: "$map.name.$template", Line: 6, Column: 5, Err: errors.New("template needs 1 parameter")},
+				{Path: "$map.$each", Line: 7, Column: 3, Err: errors.New("mapping mixes the executors and the fields")},
+			},
+		},
+		{
+			`
+$map:
+  items:
+    $each:
+      - $slice: x
+      - $map: {}`,
+			[]SchemaError{
+				{Path: "$map.items.$each.0.$slice", Line: 5, Column: 9, Err: errors.New("invalid slice limit x")},
+				{Path: "$map.items.$each.1.$map", Line: 6, Column: 15, Err: errors.New("empty mapping")},
+			},
+		},
+		{
+			`
+$pipe:
+  - $first: []
+  - $ref: missing`,
+			[]SchemaError{
+				{Path: "$pipe.0.$first", Line: 3, Column: 5, Err: errors.New("first needs at least 1 parameter")},
+				{Path: "$pipe.1.$ref", Line: 4, Column: 5, Err: errors.New("line 4 column 5 ref: definition missing not found")},
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		err := Validate(c.schema)
+		var problems ValidateError
+		if !assert.ErrorAs(t, err, &problems) {
+			continue
+		}
+		if !assert.Len(t, problems, len(c.problems), err.Error()) {
+			continue
+		}
+		for i, p := range c.problems {
+			assert.Equal(t, p.Path, problems[i].Path)
+			assert.Equal(t, p.Line, problems[i].Line, p.Path)
+			assert.Equal(t, p.Column, problems[i].Column, p.Path)
+			assert.EqualError(t, problems[i].Err, p.Err.Error())
+		}
+		var schemaErr *SchemaError
+		assert.ErrorAs(t, err, &schemaErr)
+	}
+
+	err := Validate(`$template: [a, b]`)
+	assert.EqualError(t, err, "invalid schema: $template line 1 column 1: template needs 1 parameter")
+}
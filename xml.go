@@ -0,0 +1,79 @@
+package ski
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// xmlToJSON converts the XML/HTML string to the JSON object,
+// the attributes are prefixed with "@", the text content is "#text",
+// the repeated child elements become an array, and the element
+// contains only text becomes the string.
+//
+//	<user id="1"><name>foo</name><tag>a</tag><tag>b</tag></user>
+//	{"user": {"@id": "1", "name": "foo", "tag": ["a", "b"]}}
+func xmlToJSON(str string) (any, error) {
+	decoder := xml.NewDecoder(strings.NewReader(str))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	root := map[string]any{}
+	type frame struct {
+		name string
+		obj  map[string]any
+		text strings.Builder
+	}
+	stack := []*frame{{obj: root}}
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			f := &frame{name: t.Name.Local, obj: make(map[string]any, len(t.Attr))}
+			for _, attr := range t.Attr {
+				f.obj["@"+attr.Name.Local] = attr.Value
+			}
+			stack = append(stack, f)
+		case xml.CharData:
+			stack[len(stack)-1].text.Write(t)
+		case xml.EndElement:
+			if len(stack) < 2 {
+				return nil, errors.New("unexpected end element " + t.Name.Local)
+			}
+			f := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			var value any = f.obj
+			text := strings.TrimSpace(f.text.String())
+			if len(f.obj) == 0 {
+				value = text
+			} else if text != "" {
+				f.obj["#text"] = text
+			}
+
+			parent := stack[len(stack)-1].obj
+			switch exists := parent[f.name].(type) {
+			case nil:
+				parent[f.name] = value
+			case []any:
+				parent[f.name] = append(exists, value)
+			default:
+				parent[f.name] = []any{exists, value}
+			}
+		}
+	}
+
+	if len(root) == 0 {
+		return nil, errors.New("no XML element found")
+	}
+	return root, nil
+}
@@ -3,7 +3,10 @@ package xpath
 
 import (
 	"context"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/antchfx/htmlquery"
@@ -112,8 +115,64 @@ func htmlNode(content any) (*html.Node, error) {
 	case *html.Node:
 		return data, nil
 	case []string:
-		return html.Parse(strings.NewReader(strings.Join(data, "\n")))
+		return parse(strings.Join(data, "\n"))
 	case string:
-		return html.Parse(strings.NewReader(data))
+		return parse(data)
+	}
+}
+
+// parse parses the HTML, or the XML if the content starts with the XML declaration,
+// the malformed XML, eg: the unexpected end element, is parsed as the HTML.
+func parse(content string) (*html.Node, error) {
+	if strings.HasPrefix(strings.TrimSpace(strings.TrimPrefix(content, "\ufeff")), "<?xml") {
+		if node, err := xmlNode(content); err == nil {
+			return node, nil
+		}
+	}
+	return html.Parse(strings.NewReader(content))
+}
+
+// xmlNode parses the XML to the html.Node tree, unlike the HTML parser the element names
+// are case-sensitive, the self-closing elements and the CDATA sections are supported.
+// The namespaces are dropped, the elements are matched by the local name. eg: "//dc:creator" is "//creator".
+func xmlNode(content string) (*html.Node, error) {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	// the content is already the UTF-8 string, the declared encoding is ignored
+	decoder.CharsetReader = func(_ string, r io.Reader) (io.Reader, error) { return r, nil }
+
+	root := &html.Node{Type: html.DocumentNode}
+	parent := root
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return root, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &html.Node{Type: html.ElementNode, Data: t.Name.Local}
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+					continue
+				}
+				node.Attr = append(node.Attr, html.Attribute{Key: attr.Name.Local, Val: attr.Value})
+			}
+			parent.AppendChild(node)
+			parent = node
+		case xml.EndElement:
+			if parent.Parent != nil {
+				parent = parent.Parent
+			}
+		case xml.CharData:
+			if parent != root {
+				parent.AppendChild(&html.Node{Type: html.TextNode, Data: string(t)})
+			}
+		case xml.Comment:
+			parent.AppendChild(&html.Node{Type: html.CommentNode, Data: string(t)})
+		}
 	}
 }
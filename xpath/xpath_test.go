@@ -137,3 +137,74 @@ func TestElements(t *testing.T) {
 		"<class>five even row odder</class>", "<class>six odd row</class>",
 	})
 }
+
+func TestXML(t *testing.T) {
+	t.Parallel()
+	const feed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel>
+    <title>Feed</title>
+    <item>
+      <title><![CDATA[First <b>post</b>]]></title>
+      <pubDate>Mon, 02 Jan 2006</pubDate>
+      <enclosure url="https://example.com/1.mp3" type="audio/mpeg"/>
+      <dc:creator>foo</dc:creator>
+    </item>
+    <item>
+      <title>Second &amp; last</title>
+      <pubDate>Tue, 03 Jan 2006</pubDate>
+      <enclosure url="https://example.com/2.mp3" type="audio/mpeg"/>
+      <dc:creator>bar</dc:creator>
+    </item>
+  </channel>
+</rss>`
+
+	testCases := []struct {
+		expr string
+		want any
+	}{
+		{`//channel/title`, "Feed"},
+		{`//item/title`, ski.NewIterator([]string{"First <b>post</b>", "Second & last"})},
+		{`//item/pubDate/text()`, ski.NewIterator([]string{"Mon, 02 Jan 2006", "Tue, 03 Jan 2006"})},
+		{`//item[2]/enclosure/@url`, "https://example.com/2.mp3"},
+		{`//item[creator="foo"]/pubDate`, "Mon, 02 Jan 2006"},
+		{`//item[enclosure/@url="https://example.com/2.mp3"]/title`, "Second & last"},
+		{`/rss/@version`, "2.0"},
+		{`//pubdate`, nil},
+	}
+	for _, c := range testCases {
+		t.Run(c.expr, func(t *testing.T) {
+			exec, err := new_value()(ski.String(c.expr))
+			if !assert.NoError(t, err) {
+				return
+			}
+			v, err := exec.Exec(ctx, feed)
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.want, v)
+			}
+		})
+	}
+
+	exec, err := new_elements()(ski.String(`//enclosure`))
+	if assert.NoError(t, err) {
+		v, err := exec.Exec(ctx, feed)
+		if assert.NoError(t, err) {
+			assert.Equal(t, 2, v.(ski.Iterator).Len())
+		}
+	}
+
+	// the declared encoding is ignored, the malformed XML is parsed as the HTML
+	for content, want := range map[string]string{
+		`<?xml version="1.0" encoding="GBK"?><html><body><p>你好</p></body></html>`:          "你好",
+		`<?xml version="1.0" encoding="ISO-8859-1"?><html><body><p>café</p></body></html>`: "café",
+		`<?xml version="1.0"?><html><body><p>foo</p></div></body></html>`:                  "foo",
+	} {
+		exec, err := new_value()(ski.String(`//p`))
+		if assert.NoError(t, err) {
+			v, err := exec.Exec(ctx, content)
+			if assert.NoError(t, err, content) {
+				assert.Equal(t, want, v, content)
+			}
+		}
+	}
+}